@@ -2,11 +2,14 @@ package config
 
 import (
 	"context"
+	"fmt"
 	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"iter"
+	"reflect"
 )
 
 var (
-	r = registry.New[Producer](context.Background())
+	r = registry.Global().Configs()
 )
 
 // Register function produces pluggable solution for Config Producer implementations.
@@ -14,8 +17,117 @@ func Register(name string, instance Producer) error {
 	return r.Register(name, instance)
 }
 
+// RegisterScoped registers Producer into the scope, so it's visible from contexts carrying the scope or its
+// children only.
+func RegisterScoped(scope *registry.Scope, name string, instance Producer) error {
+	return scope.Configs().Register(name, instance)
+}
+
 // GetConfigProducer returns Producer by name from the registry. It returns error if there is no an instance for the name.
 // Also, it can return error if the result instance has unexpected type.
 func GetConfigProducer(name string) (error, *Producer) {
-	return r.Get(name)
+	err, a := r.Get(name)
+	if err != nil {
+		return err, nil
+	}
+	return toProducer(name, *a)
+}
+
+// GetConfigProducerFromContext returns Producer by name from the registry.Scope carried by the context, falling back
+// to its parents. If the context has no scope then the package-level registry is used.
+func GetConfigProducerFromContext(ctx context.Context, name string) (error, *Producer) {
+	err, a := registry.ScopeFrom(ctx).LookupConfig(name)
+	if err != nil {
+		return err, nil
+	}
+	return toProducer(name, a)
+}
+
+// Unregister removes Producer by name from the registry, so the name could be registered again.
+func Unregister(name string) error {
+	return r.Unregister(name)
+}
+
+// Replace atomically swaps Producer registered by the name and returns the previous one.
+func Replace(name string, instance Producer) (error, *Producer) {
+	err, previous := r.Replace(name, instance)
+	if err != nil {
+		return err, nil
+	}
+	return toProducer(name, *previous)
+}
+
+// Names returns sorted names of all registered Producers.
+func Names() []string {
+	return r.Names()
+}
+
+// Len returns a number of registered Producers.
+func Len() int {
+	return r.Len()
+}
+
+// Range calls fn for every registered Producer in order of names until fn returns false.
+func Range(fn func(name string, instance Producer) bool) {
+	for name, producer := range All() {
+		if !fn(name, producer) {
+			return
+		}
+	}
+}
+
+// All returns an iterator over a snapshot of registered Producers in order of names.
+func All() iter.Seq2[string, Producer] {
+	return func(yield func(string, Producer) bool) {
+		for name, a := range r.All() {
+			if producer, ok := a.(Producer); ok && !yield(name, producer) {
+				return
+			}
+		}
+	}
+}
+
+// Watch returns a channel with every change of the package-level registry, so a hot-swapped Producer could be
+// picked up. See registry.Registry Watch for the details.
+func Watch(ctx context.Context) <-chan registry.Event[Producer] {
+	events := make(chan registry.Event[Producer])
+	source := r.Watch(ctx)
+	go func() {
+		defer close(events)
+		for event := range source {
+			select {
+			case events <- toEvent(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// Subscribe calls fn for every change of the package-level registry. See registry.Registry Subscribe for
+// the details.
+func Subscribe(ctx context.Context, fn func(event registry.Event[Producer])) {
+	r.Subscribe(ctx, func(event registry.Event[any]) {
+		fn(toEvent(event))
+	})
+}
+
+func toEvent(event registry.Event[any]) registry.Event[Producer] {
+	typed := registry.Event[Producer]{Kind: event.Kind, Name: event.Name}
+	typed.Instance, _ = event.Instance.(Producer)
+	typed.Previous, _ = event.Previous.(Producer)
+	return typed
+}
+
+func toProducer(name string, a any) (error, *Producer) {
+	if producer, ok := a.(Producer); ok {
+		return nil, &producer
+	}
+	return fmt.Errorf(
+		"%s instance has unexpected type %s, expected type %s",
+		name,
+		reflect.TypeOf(a),
+		reflect.TypeOf((*Producer)(nil)),
+	), nil
 }
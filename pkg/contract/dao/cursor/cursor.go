@@ -0,0 +1,306 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Version is a version of the token format produced by Codec.
+//
+// The token of version 1 is a binary sequence:
+//
+//	version (1 byte) | flags (1 byte) | expiresAt (varint, unix seconds, 0 - never) | offset (uvarint) |
+//	keys count (uvarint) | keys | HMAC-SHA256 of all previous bytes (16 bytes)
+//
+// Every key is a type tag (1 byte) followed by its value. A time is encoded as unix seconds (varint) and nanoseconds
+// (uvarint), so every time.Time is kept exactly.
+const Version byte = 1
+
+// MinKeySize is a minimal size of a signing key.
+const MinKeySize = 16
+
+const signatureSize = 16
+
+const flagBackward byte = 1
+
+var (
+	// ErrMalformed is returned when the token can't be parsed.
+	ErrMalformed = errors.New("cursor token is malformed")
+
+	// ErrUnsupportedVersion is returned when the token was produced by an unknown version of the format.
+	ErrUnsupportedVersion = errors.New("cursor token version isn't supported")
+
+	// ErrInvalidSignature is returned when the token was changed or signed by unknown key.
+	ErrInvalidSignature = errors.New("cursor token signature is invalid")
+
+	// ErrExpired is returned when the token is expired.
+	ErrExpired = errors.New("cursor token is expired")
+)
+
+const (
+	tagNil byte = iota
+	tagString
+	tagInt
+	tagUint
+	tagFloat
+	tagBool
+	tagTime
+	tagBytes
+)
+
+// Cursor is a position of a page. Offset based implementations use Offset, keyset based implementations use Keys
+// with sort key values of the boundary item.
+type Cursor struct {
+
+	// Offset of the page start.
+	Offset uint64
+
+	// Keys are sort key values of the boundary item. Supported values are nil, string, bool, []byte, time.Time,
+	// signed and unsigned integers and floats. They are decoded as nil, string, bool, []byte, time.Time (UTC),
+	// int64, uint64 and float64.
+	Keys []any
+
+	// Backward defines whether the page goes before the boundary item.
+	Backward bool
+
+	// ExpiresAt is a moment after which the token is rejected. It's optional. If it's zero then Codec TTL is
+	// applied by Encode. Its precision is a second.
+	ExpiresAt time.Time
+}
+
+// Codec encodes cursors into signed tokens for Pagination.NextToken and Pagination.PrevToken and decodes them back.
+// The tokens are opaque for clients, they can't be changed without the key. Note that the tokens aren't encrypted,
+// so the cursor content is visible for a client. The Codec is safe for concurrent use.
+type Codec struct {
+	keys [][]byte
+	ttl  time.Duration
+}
+
+// NewCodec creates a Codec. The key signs new tokens. The previous keys only verify tokens, so the key could be
+// rotated without breaking issued tokens. If ttl isn't 0 then every token expires after it.
+func NewCodec(key []byte, ttl time.Duration, previous ...[]byte) (error, *Codec) {
+	keys := make([][]byte, 0, len(previous)+1)
+	for _, k := range append([][]byte{key}, previous...) {
+		if len(k) < MinKeySize {
+			return fmt.Errorf("cursor key should have at least %d bytes", MinKeySize), nil
+		}
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	if ttl < 0 {
+		return errors.New("cursor ttl should not be negative"), nil
+	}
+	return nil, &Codec{keys: keys, ttl: ttl}
+}
+
+// Encode produces a signed token for the cursor.
+func (c *Codec) Encode(cur Cursor) (error, []byte) {
+	expiresAt := cur.ExpiresAt
+	if expiresAt.IsZero() && c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	var flags byte
+	if cur.Backward {
+		flags |= flagBackward
+	}
+
+	buf := []byte{Version, flags}
+	if expiresAt.IsZero() {
+		buf = binary.AppendVarint(buf, 0)
+	} else {
+		buf = binary.AppendVarint(buf, expiresAt.Unix())
+	}
+	buf = binary.AppendUvarint(buf, cur.Offset)
+	buf = binary.AppendUvarint(buf, uint64(len(cur.Keys)))
+	for i, key := range cur.Keys {
+		var err error
+		err, buf = appendKey(buf, key)
+		if err != nil {
+			return fmt.Errorf("cursor key %d: %w", i, err), nil
+		}
+	}
+	return nil, append(buf, sign(c.keys[0], buf)...)
+}
+
+// Decode verifies the token and returns its cursor. It returns ErrMalformed, ErrUnsupportedVersion,
+// ErrInvalidSignature or ErrExpired if the token can't be accepted.
+func (c *Codec) Decode(token []byte) (error, Cursor) {
+	if len(token) < 2+signatureSize {
+		return ErrMalformed, Cursor{}
+	}
+	if token[0] != Version {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, token[0]), Cursor{}
+	}
+
+	body, signature := token[:len(token)-signatureSize], token[len(token)-signatureSize:]
+	verified := false
+	for _, key := range c.keys {
+		if hmac.Equal(sign(key, body), signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return ErrInvalidSignature, Cursor{}
+	}
+
+	r := &reader{buf: body[1:]}
+	flags := r.byte()
+	expiresAt := r.varint()
+	cur := Cursor{
+		Offset:   r.uvarint(),
+		Backward: flags&flagBackward != 0,
+	}
+	count := r.uvarint()
+	if r.err == nil && count > uint64(len(r.buf)) {
+		r.err = ErrMalformed
+	}
+	if r.err == nil && count > 0 {
+		cur.Keys = make([]any, 0, count)
+		for i := uint64(0); i < count && r.err == nil; i++ {
+			cur.Keys = append(cur.Keys, r.key())
+		}
+	}
+	if r.err != nil || len(r.buf) != 0 {
+		return ErrMalformed, Cursor{}
+	}
+
+	if expiresAt != 0 {
+		cur.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+		if !time.Now().Before(cur.ExpiresAt) {
+			return ErrExpired, Cursor{}
+		}
+	}
+	return nil, cur
+}
+
+func sign(key []byte, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)[:signatureSize]
+}
+
+func appendKey(buf []byte, key any) (error, []byte) {
+	switch v := key.(type) {
+	case nil:
+		return nil, append(buf, tagNil)
+	case string:
+		buf = binary.AppendUvarint(append(buf, tagString), uint64(len(v)))
+		return nil, append(buf, v...)
+	case []byte:
+		buf = binary.AppendUvarint(append(buf, tagBytes), uint64(len(v)))
+		return nil, append(buf, v...)
+	case bool:
+		if v {
+			return nil, append(buf, tagBool, 1)
+		}
+		return nil, append(buf, tagBool, 0)
+	case time.Time:
+		buf = binary.AppendVarint(append(buf, tagTime), v.Unix())
+		return nil, binary.AppendUvarint(buf, uint64(v.Nanosecond()))
+	}
+
+	rv := reflect.ValueOf(key)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return nil, binary.AppendVarint(append(buf, tagInt), rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return nil, binary.AppendUvarint(append(buf, tagUint), rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return nil, binary.BigEndian.AppendUint64(append(buf, tagFloat), math.Float64bits(rv.Float()))
+	case reflect.String:
+		return appendKey(buf, rv.String())
+	}
+	return fmt.Errorf("value type %T isn't supported", key), nil
+}
+
+// reader reads the token body. The first error stops reading, every next call returns a zero value.
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil || len(r.buf) < 1 {
+		r.err = ErrMalformed
+		return 0
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *reader) bytes(n uint64) []byte {
+	if r.err != nil || uint64(len(r.buf)) < n {
+		r.err = ErrMalformed
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		r.err = ErrMalformed
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = ErrMalformed
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *reader) key() any {
+	switch tag := r.byte(); tag {
+	case tagNil:
+		return nil
+	case tagString:
+		return string(r.bytes(r.uvarint()))
+	case tagBytes:
+		return append([]byte{}, r.bytes(r.uvarint())...)
+	case tagBool:
+		return r.byte() == 1
+	case tagTime:
+		sec := r.varint()
+		nsec := r.uvarint()
+		if nsec >= uint64(time.Second) {
+			r.err = ErrMalformed
+			return nil
+		}
+		return time.Unix(sec, int64(nsec)).UTC()
+	case tagInt:
+		return r.varint()
+	case tagUint:
+		return r.uvarint()
+	case tagFloat:
+		b := r.bytes(8)
+		if b == nil {
+			return nil
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	r.err = ErrMalformed
+	return nil
+}
@@ -0,0 +1,143 @@
+package dao
+
+import (
+	"bytes"
+	"context"
+	"iter"
+)
+
+// All returns an iterator over every entity found by Read. It follows Pagination.NextToken from page to page until
+// HasNext is false or no token comes back. The request isn't changed. If the request has no Pagination then
+// the default page size of the implementation is used.
+//
+// The iteration stops after the first error which is yielded with a zero entity, e.g. a context cancellation error.
+func All[K any, T any, F any](ctx context.Context, d DAO[K, T, F], request *ReadRequest[F]) iter.Seq2[T, error] {
+	return items(Pages(ctx, d, request), func(response *ReadResponse[T]) []T { return response.Data })
+}
+
+// Pages returns an iterator over every page returned by Read. See All for the details.
+func Pages[K any, T any, F any](ctx context.Context, d DAO[K, T, F], request *ReadRequest[F]) iter.Seq2[*ReadResponse[T], error] {
+	r := ReadRequest[F]{}
+	if request != nil {
+		r = *request
+	}
+	return pages(ctx, r.Pagination, func(pagination *Pagination) (error, *ReadResponse[T], *Pagination) {
+		r.Pagination = pagination
+		err, response := d.Read(ctx, &r)
+		if err != nil {
+			return err, nil, nil
+		}
+		if response == nil {
+			return nil, &ReadResponse[T]{}, nil
+		}
+		return nil, response, response.Pagination
+	})
+}
+
+// BulkReadAll returns an iterator over every entity found by BulkRead. See All for the details.
+func BulkReadAll[K any, T any, F any](ctx context.Context, d DAO[K, T, F], request *BulkReadRequest[F]) iter.Seq2[T, error] {
+	return items(BulkReadPages(ctx, d, request), func(response *BulkReadResponse[T]) []T { return response.Data })
+}
+
+// BulkReadPages returns an iterator over every page returned by BulkRead. See All for the details.
+func BulkReadPages[K any, T any, F any](ctx context.Context, d DAO[K, T, F], request *BulkReadRequest[F]) iter.Seq2[*BulkReadResponse[T], error] {
+	r := BulkReadRequest[F]{}
+	if request != nil {
+		r = *request
+	}
+	return pages(ctx, r.Pagination, func(pagination *Pagination) (error, *BulkReadResponse[T], *Pagination) {
+		r.Pagination = pagination
+		err, response := d.BulkRead(ctx, &r)
+		if err != nil {
+			return err, nil, nil
+		}
+		if response == nil {
+			return nil, &BulkReadResponse[T]{}, nil
+		}
+		return nil, response, response.Pagination
+	})
+}
+
+// RangeReadAll returns an iterator over every entity found by RangeRead. See All for the details.
+func RangeReadAll[K any, T any, F any](ctx context.Context, d DAO[K, T, F], request *RangeReadRequest[F]) iter.Seq2[T, error] {
+	return items(RangeReadPages(ctx, d, request), func(response *RangeReadResponse[T]) []T { return response.Data })
+}
+
+// RangeReadPages returns an iterator over every page returned by RangeRead. See All for the details.
+func RangeReadPages[K any, T any, F any](ctx context.Context, d DAO[K, T, F], request *RangeReadRequest[F]) iter.Seq2[*RangeReadResponse[T], error] {
+	r := RangeReadRequest[F]{}
+	if request != nil {
+		r = *request
+	}
+	return pages(ctx, r.Pagination, func(pagination *Pagination) (error, *RangeReadResponse[T], *Pagination) {
+		r.Pagination = pagination
+		err, response := d.RangeRead(ctx, &r)
+		if err != nil {
+			return err, nil, nil
+		}
+		if response == nil {
+			return nil, &RangeReadResponse[T]{}, nil
+		}
+		return nil, response, response.Pagination
+	})
+}
+
+// pages calls read for the first page and then for every next page. The next request keeps the page size and
+// TotalMode of the first one and carries the NextToken of the previous response. It stops when there is no next page or the token
+// doesn't change, so a broken implementation can't make it loop forever.
+func pages[R any](ctx context.Context, first *Pagination, read func(pagination *Pagination) (error, R, *Pagination)) iter.Seq2[R, error] {
+	return func(yield func(R, error) bool) {
+		var zero R
+		pagination := &Pagination{}
+		if first != nil {
+			*pagination = *first
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			err, response, next := read(pagination)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(response, nil) {
+				return
+			}
+
+			if next == nil || next.NextToken == nil || len(*next.NextToken) == 0 {
+				return
+			}
+			if next.HasNext != nil && !*next.HasNext {
+				return
+			}
+			if pagination.NextToken != nil && bytes.Equal(*pagination.NextToken, *next.NextToken) {
+				return
+			}
+
+			token := append([]byte(nil), *next.NextToken...)
+			pagination = &Pagination{Size: pagination.Size, NextToken: &token, TotalMode: pagination.TotalMode}
+		}
+	}
+}
+
+// items flattens the page iterator into an iterator over entities.
+func items[R any, T any](pages iter.Seq2[R, error], data func(response R) []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for response, err := range pages {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range data(response) {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrCircularDependency is returned when providers ask for each other while building their instances.
+var ErrCircularDependency = errors.New("circular dao provider dependency")
+
+// ProviderFunc builds a DAO instance. Providers which depend on other DAOs must resolve them by GetDAOFromContext
+// with the given context, so circular dependencies are detected instead of blocking forever. The context carries
+// the chain of building providers, a lookup without it, e.g. by GetDAO, can't detect a cycle and blocks.
+type ProviderFunc[K any, T any, F any] func(ctx context.Context) (error, DAO[K, T, F])
+
+// provider is kept in the registry instead of an instance. It builds the instance on the first lookup and keeps it.
+// A failed build isn't kept, so the next lookup tries again.
+type provider struct {
+	name     string
+	build    func(ctx context.Context) (error, any)
+	instance any
+	built    bool
+	mu       sync.Mutex
+}
+
+type buildingKey struct{}
+
+// RegisterProvider registers a lazy DAO. The provider is called on the first GetDAO, GetDAOFromContext or factory
+// Make for the name, so backends which are never used are never built. If the provider returns an error then
+// the error is returned by the lookup and the provider is called again on the next one.
+func RegisterProvider[K any, T any, F any](name string, fn ProviderFunc[K, T, F]) error {
+	if fn == nil {
+		return errors.New(name + " dao provider is nil")
+	}
+	return r.Register(name, newProvider(name, fn))
+}
+
+// RegisterScopedProvider registers a lazy DAO into the scope. See RegisterProvider for the details.
+func RegisterScopedProvider[K any, T any, F any](scope *registry.Scope, name string, fn ProviderFunc[K, T, F]) error {
+	if fn == nil {
+		return errors.New(name + " dao provider is nil")
+	}
+	return scope.DAOs().Register(name, newProvider(name, fn))
+}
+
+func newProvider[K any, T any, F any](name string, fn ProviderFunc[K, T, F]) *provider {
+	return &provider{
+		name: name,
+		build: func(ctx context.Context) (error, any) {
+			err, instance := fn(ctx)
+			if err != nil {
+				return err, nil
+			}
+			if instance == nil {
+				return errors.New(name + " dao provider returned nil instance"), nil
+			}
+			return nil, instance
+		},
+	}
+}
+
+// get returns the instance building it if it's needed.
+func (p *provider) get(ctx context.Context) (error, any) {
+	if err := p.check(ctx); err != nil {
+		return err, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.built {
+		return nil, p.instance
+	}
+	err, instance := p.fresh(ctx)
+	if err != nil {
+		return err, nil
+	}
+	p.instance, p.built = instance, true
+	return nil, instance
+}
+
+// fresh builds a new instance which isn't kept by the provider.
+func (p *provider) fresh(ctx context.Context) (error, any) {
+	if err := p.check(ctx); err != nil {
+		return err, nil
+	}
+	chain, _ := ctx.Value(buildingKey{}).([]string)
+	err, instance := p.build(context.WithValue(ctx, buildingKey{}, append(slices.Clip(chain), p.name)))
+	if err != nil {
+		return fmt.Errorf("%s dao provider failed: %w", p.name, err), nil
+	}
+	return nil, instance
+}
+
+// check fails if the provider is building in the current call chain. The names of providers which are building
+// are kept by the context, so a provider which is asked for again by its own dependency fails instead of blocking.
+func (p *provider) check(ctx context.Context) error {
+	chain, _ := ctx.Value(buildingKey{}).([]string)
+	if slices.Contains(chain, p.name) {
+		return fmt.Errorf("%w: %s", ErrCircularDependency, strings.Join(append(chain, p.name), " -> "))
+	}
+	return nil
+}
+
+// peek returns the instance if it's already built.
+func (p *provider) peek() (any, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.instance, p.built
+}
+
+// NewDAOFromContext builds a new DAO by the provider registered by the name in the registry.Scope carried by
+// the context, falling back to its parents. The instance isn't kept, every call builds a new one. It returns error
+// if the name is registered with an instance instead of a provider.
+func NewDAOFromContext[T any](ctx context.Context, name string) (error, *T) {
+	err, a := registry.ScopeFrom(ctx).LookupDAO(name)
+	if err != nil {
+		return err, nil
+	}
+	p, ok := unwrap(a).(*provider)
+	if !ok {
+		return errors.New(name + " dao isn't registered by a provider"), nil
+	}
+	err, instance := p.fresh(ctx)
+	if err != nil {
+		return err, nil
+	}
+	return toDAO[T](instance)
+}
+
+// resolve returns the registered instance building it by its provider if it's needed.
+func resolve(ctx context.Context, a any) (error, any) {
+	a = unwrap(a)
+	if p, ok := a.(*provider); ok {
+		return p.get(ctx)
+	}
+	return nil, a
+}
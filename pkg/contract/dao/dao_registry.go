@@ -4,11 +4,12 @@ import (
 	"context"
 	"fmt"
 	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"iter"
 	"reflect"
 )
 
 var (
-	r = registry.New[any](context.Background())
+	r = registry.Global().DAOs()
 )
 
 // Register function produces pluggable solution for DAO implementations.
@@ -17,15 +18,42 @@ func Register[D any](name string, instance D) error {
 	return r.Register(name, a)
 }
 
+// RegisterScoped registers DAO into the scope, so it's visible from contexts carrying the scope or its children only.
+func RegisterScoped[D any](scope *registry.Scope, name string, instance D) error {
+	return scope.DAOs().Register(name, toAny(instance))
+}
+
 // GetDAO returns DAO by name from the registry. It returns error if there is no an instance for the name.
-// Also, it can return error if the result instance has unexpected type.
+// Also, it can return error if the result instance has unexpected type. Providers must not use it for their
+// dependencies, see ProviderFunc.
 func GetDAO[T any](name string) (error, *T) {
 	err, a := r.Get(name)
 	if err != nil {
 		return err, nil
 	}
 
-	dr := *a
+	err, instance := resolve(context.Background(), *a)
+	if err != nil {
+		return err, nil
+	}
+	return toDAO[T](instance)
+}
+
+// GetDAOFromContext returns DAO by name from the registry.Scope carried by the context, falling back to its parents.
+// If the context has no scope then the package-level registry is used.
+func GetDAOFromContext[T any](ctx context.Context, name string) (error, *T) {
+	err, a := registry.ScopeFrom(ctx).LookupDAO(name)
+	if err != nil {
+		return err, nil
+	}
+	err, instance := resolve(ctx, a)
+	if err != nil {
+		return err, nil
+	}
+	return toDAO[T](instance)
+}
+
+func toDAO[T any](dr any) (error, *T) {
 	if cast, ok := dr.(T); ok {
 		return nil, &cast
 	}
@@ -36,6 +64,113 @@ func GetDAO[T any](name string) (error, *T) {
 	), nil
 }
 
+// Unregister removes DAO by name from the registry, so the name could be registered again. It doesn't close
+// the instance.
+func Unregister(name string) error {
+	return r.Unregister(name)
+}
+
+// Replace atomically swaps DAO registered by the name and returns the previous instance, e.g. to close it.
+// If the previous DAO was registered by a provider which hasn't built it yet then nil is returned.
+func Replace[D any](name string, instance D) (error, any) {
+	err, previous := r.Replace(name, toAny(instance))
+	if err != nil {
+		return err, nil
+	}
+	return nil, peek(*previous)
+}
+
+// Names returns sorted names of all registered DAOs.
+func Names() []string {
+	return r.Names()
+}
+
+// Len returns a number of registered DAOs.
+func Len() int {
+	return r.Len()
+}
+
+// Range calls fn for every registered DAO in order of names until fn returns false. See Instances for the details.
+func Range(fn func(name string, instance any) bool) {
+	for name, instance := range Instances() {
+		if !fn(name, instance) {
+			return
+		}
+	}
+}
+
+// Instances returns an iterator over a snapshot of registered DAOs in order of names. DAOs registered by providers
+// are skipped until they're built, so the iteration never builds them. Names lists all of them.
+func Instances() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for name, a := range r.All() {
+			a = unwrap(a)
+			if p, ok := a.(*provider); ok {
+				instance, built := p.peek()
+				if !built {
+					continue
+				}
+				a = instance
+			}
+			if !yield(name, a) {
+				return
+			}
+		}
+	}
+}
+
+// Watch returns a channel with every change of the package-level registry as events typed by D, e.g.
+// DAO[K, T, F]. See registry.Registry Watch for the details. Every change is delivered, an instance which isn't
+// of type D is a zero value. Instance of a provider registration is zero since it isn't built yet, Previous
+// of a provider is the instance it has built or zero.
+func Watch[D any](ctx context.Context) <-chan registry.Event[D] {
+	events := make(chan registry.Event[D])
+	source := r.Watch(ctx)
+	go func() {
+		defer close(events)
+		for event := range source {
+			select {
+			case events <- toEvent[D](event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// Subscribe calls fn for every change of the package-level registry with events typed by D. See Watch and
+// registry.Registry Subscribe for the details.
+func Subscribe[D any](ctx context.Context, fn func(event registry.Event[D])) {
+	r.Subscribe(ctx, func(event registry.Event[any]) {
+		fn(toEvent[D](event))
+	})
+}
+
+// toEvent replaces registered values of the event with their DAOs of type D.
+func toEvent[D any](event registry.Event[any]) registry.Event[D] {
+	typed := registry.Event[D]{Kind: event.Kind, Name: event.Name}
+	if _, ok := unwrap(event.Instance).(*provider); !ok {
+		if instance, ok := unwrap(event.Instance).(D); ok {
+			typed.Instance = instance
+		}
+	}
+	if previous, ok := peek(event.Previous).(D); ok {
+		typed.Previous = previous
+	}
+	return typed
+}
+
+// peek returns the DAO of the registered value without building it.
+func peek(a any) any {
+	a = unwrap(a)
+	if p, ok := a.(*provider); ok {
+		instance, _ := p.peek()
+		return instance
+	}
+	return a
+}
+
 func toAny[T any](value T) any {
 	return value
 }
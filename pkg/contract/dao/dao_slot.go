@@ -0,0 +1,118 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// Slot is a typed handle of a registry name. The same Slot is used for registration and lookup, so the key, entity
+// and filter types of both sides are checked by the compiler. Slots are usually declared once as package variables:
+//
+//	var Users = dao.NewSlot[string, User, UserFilter]("users")
+type Slot[K any, T any, F any] struct {
+	name string
+}
+
+// Backend describes a DAO registered through a Slot.
+type Backend struct {
+
+	// Name of the DAO in the registry.
+	Name string
+
+	// Key is a type of entity keys.
+	Key reflect.Type
+
+	// Entity is a type of entities.
+	Entity reflect.Type
+
+	// Filter is a type of filters.
+	Filter reflect.Type
+}
+
+// slotted is kept in the registry instead of a value registered through a Slot, so the slot description is removed
+// together with the value. The value is an instance or a provider.
+type slotted struct {
+	backend Backend
+	value   any
+}
+
+// NewSlot creates a typed handle of the name.
+func NewSlot[K any, T any, F any](name string) Slot[K, T, F] {
+	return Slot[K, T, F]{name: name}
+}
+
+// Name returns the registry name of the slot.
+func (s Slot[K, T, F]) Name() string {
+	return s.name
+}
+
+// Register registers the instance into the registry.Scope carried by the context or into the package-level
+// registry if there is no scope.
+func (s Slot[K, T, F]) Register(ctx context.Context, instance DAO[K, T, F]) error {
+	return s.register(ctx, instance)
+}
+
+// RegisterProvider registers a lazy DAO into the registry.Scope carried by the context or into the package-level
+// registry if there is no scope. See RegisterProvider function for the details.
+func (s Slot[K, T, F]) RegisterProvider(ctx context.Context, fn ProviderFunc[K, T, F]) error {
+	if fn == nil {
+		return errors.New(s.name + " dao provider is nil")
+	}
+	return s.register(ctx, newProvider(s.name, fn))
+}
+
+// Get returns the DAO resolved by GetDAOFromContext.
+func (s Slot[K, T, F]) Get(ctx context.Context) (error, DAO[K, T, F]) {
+	err, d := GetDAOFromContext[DAO[K, T, F]](ctx, s.name)
+	if err != nil {
+		return err, nil
+	}
+	return nil, *d
+}
+
+// Backend describes the slot types.
+func (s Slot[K, T, F]) Backend() Backend {
+	return Backend{
+		Name:   s.name,
+		Key:    reflect.TypeOf((*K)(nil)).Elem(),
+		Entity: reflect.TypeOf((*T)(nil)).Elem(),
+		Filter: reflect.TypeOf((*F)(nil)).Elem(),
+	}
+}
+
+func (s Slot[K, T, F]) register(ctx context.Context, instance any) error {
+	return registry.ScopeFrom(ctx).DAOs().Register(s.name, &slotted{backend: s.Backend(), value: instance})
+}
+
+// Backends returns DAOs registered through slots which are visible from the registry.Scope carried by the context,
+// including the parent scopes, sorted by names. A name of a scope hides the same name of its parents. It lets
+// a process enumerate which entity types have backends.
+func Backends(ctx context.Context) []Backend {
+	result := make([]Backend, 0)
+	seen := make([]string, 0)
+	for scope := registry.ScopeFrom(ctx); scope != nil; scope = scope.Parent() {
+		for name, a := range scope.DAOs().All() {
+			if slices.Contains(seen, name) {
+				continue
+			}
+			seen = append(seen, name)
+			if s, ok := a.(*slotted); ok {
+				result = append(result, s.backend)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// unwrap returns the registered value without the slot description.
+func unwrap(a any) any {
+	if s, ok := a.(*slotted); ok {
+		return s.value
+	}
+	return a
+}
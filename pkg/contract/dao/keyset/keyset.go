@@ -0,0 +1,198 @@
+package keyset
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/dao/cursor"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+)
+
+// SortKey is a field of the sort order.
+type SortKey struct {
+
+	// Field is a name of the field used in filters.
+	Field string
+
+	// Descending defines whether the field is sorted from the greatest value to the lowest one.
+	Descending bool
+}
+
+// KeyFunc extracts values of the sort keys from the item in the order of the sort keys.
+type KeyFunc[T any] func(item T) []any
+
+// Paginator implements keyset (seek) pagination. Instead of skipping Offset items it remembers the sort key values
+// of the boundary item in the page token and builds a predicate which selects the items after (or before) it,
+// so deep pages are cheap and stable under concurrent writes.
+//
+// The sort order must be total, i.e. the last sort key should be unique, e.g. an identifier, and the sort key values
+// must not be null. A typical request flow is:
+//
+//  1. Build the query filter with Filter and take the direction it returns.
+//  2. Sort by Sort of the direction and fetch at most Size + 1 items.
+//  3. Pass the fetched items to Page and return its items and pagination.
+type Paginator[T any] struct {
+	codec   *cursor.Codec
+	extract KeyFunc[T]
+	keys    []SortKey
+}
+
+// New creates a Paginator. The codec signs page tokens, the extract function returns values of the sort keys.
+func New[T any](codec *cursor.Codec, extract KeyFunc[T], keys ...SortKey) (error, *Paginator[T]) {
+	if codec == nil {
+		return errors.New("keyset codec is required"), nil
+	}
+	if extract == nil {
+		return errors.New("keyset key function is required"), nil
+	}
+	if len(keys) == 0 {
+		return errors.New("keyset requires at least one sort key"), nil
+	}
+	for i, key := range keys {
+		if key.Field == "" {
+			return fmt.Errorf("keyset sort key %d has no field", i), nil
+		}
+	}
+	return nil, &Paginator[T]{codec: codec, extract: extract, keys: append([]SortKey(nil), keys...)}
+}
+
+// Sort returns the sort order which the query should use for the direction. A backward page is fetched in the
+// reversed order starting from the boundary item, Page restores the natural order.
+func (p *Paginator[T]) Sort(backward bool) []SortKey {
+	keys := append([]SortKey(nil), p.keys...)
+	if backward {
+		for i := range keys {
+			keys[i].Descending = !keys[i].Descending
+		}
+	}
+	return keys
+}
+
+// Filter returns the query filter for the requested page. The token is taken from NextToken or PrevToken
+// according to the Pagination priority rules and its position becomes a seek predicate joined with the base filter
+// by AND. The base filter isn't changed, it could be nil. It also returns whether the page goes backward.
+//
+// If there is no token then the base filter is returned as is and the page is the first one.
+func (p *Paginator[T]) Filter(base filter.Filter, pagination *dao.Pagination) (error, filter.Filter, bool) {
+	err, position := p.position(pagination)
+	if err != nil || position == nil {
+		return err, base, false
+	}
+
+	f := filter.New()
+	if base != nil {
+		f.And(base)
+	}
+	return nil, f.And(p.seek(position.Keys, position.Backward)), position.Backward
+}
+
+// Page trims the fetched items to the page size, restores their natural order for a backward page and builds
+// the response pagination. The items should be fetched by Sort of the direction and the filter returned by Filter,
+// at most size + 1 of them, so an extra item tells there is one more page in the direction.
+//
+// NextToken points after the last item of the page and PrevToken points before the first one. They are set only
+// when there are items in that direction.
+func (p *Paginator[T]) Page(items []T, size uint, request *dao.Pagination) (error, []T, *dao.Pagination) {
+	err, position := p.position(request)
+	if err != nil {
+		return err, nil, nil
+	}
+	backward := position != nil && position.Backward
+
+	more := uint(len(items)) > size
+	if more {
+		items = items[:size]
+	}
+	page := make([]T, len(items))
+	copy(page, items)
+	if backward {
+		for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+			page[i], page[j] = page[j], page[i]
+		}
+	}
+
+	// Moving forward there are items before the page if it was reached by a token, moving backward there are
+	// items after the page since the token came from there.
+	hasNext := more
+	hasPrev := position != nil
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+
+	response := &dao.Pagination{Size: &size, HasNext: &hasNext}
+	if len(page) == 0 {
+		hasNext = false
+		return nil, page, response
+	}
+	if hasNext {
+		err, token := p.token(page[len(page)-1], false)
+		if err != nil {
+			return err, nil, nil
+		}
+		response.NextToken = &token
+	}
+	if hasPrev {
+		err, token := p.token(page[0], true)
+		if err != nil {
+			return err, nil, nil
+		}
+		response.PrevToken = &token
+	}
+	return nil, page, response
+}
+
+// position decodes the token of the request. It returns nil if there is no token.
+func (p *Paginator[T]) position(pagination *dao.Pagination) (error, *cursor.Cursor) {
+	if pagination == nil {
+		return nil, nil
+	}
+	token := pagination.NextToken
+	if token == nil {
+		token = pagination.PrevToken
+	}
+	if token == nil {
+		return nil, nil
+	}
+
+	err, position := p.codec.Decode(*token)
+	if err != nil {
+		return err, nil
+	}
+	if len(position.Keys) != len(p.keys) {
+		return fmt.Errorf("%w: expected %d sort keys, got %d", cursor.ErrMalformed, len(p.keys), len(position.Keys)), nil
+	}
+	return nil, &position
+}
+
+func (p *Paginator[T]) token(item T, backward bool) (error, []byte) {
+	values := p.extract(item)
+	if len(values) != len(p.keys) {
+		return fmt.Errorf("keyset key function returned %d values, expected %d", len(values), len(p.keys)), nil
+	}
+	for i, value := range values {
+		if value == nil {
+			return fmt.Errorf("keyset sort key %s has null value", p.keys[i].Field), nil
+		}
+	}
+	return p.codec.Encode(cursor.Cursor{Keys: values, Backward: backward})
+}
+
+// seek builds the predicate selecting items after the position in the sort order, or before it for a backward page.
+// For sort keys (a, b, c) it's: a > va OR (a = va AND b > vb) OR (a = va AND b = vb AND c > vc), where the comparison
+// of every key follows its direction.
+func (p *Paginator[T]) seek(values []any, backward bool) filter.Filter {
+	f := filter.New()
+	for i, key := range p.keys {
+		alternative := filter.New()
+		for j := 0; j < i; j++ {
+			alternative.AddField(filter.NewFieldExpression(p.keys[j].Field, filter.NewExpression(filter.Eq, values[j])))
+		}
+		op := filter.Gt
+		if key.Descending != backward {
+			op = filter.Lt
+		}
+		alternative.AddField(filter.NewFieldExpression(key.Field, filter.NewExpression(op, values[i])))
+		f.Or(alternative)
+	}
+	return f
+}
@@ -1,5 +1,39 @@
 package dao
 
+import (
+	"errors"
+	"fmt"
+)
+
+// TotalMode defines how Pagination.Total is counted.
+type TotalMode int
+
+// TotalExact is an exact number of entities. It could be expensive for large storages.
+var TotalExact TotalMode = 1
+
+// TotalEstimated is an approximate number of entities, e.g. taken from storage statistics or extrapolated from
+// a sample. It's cheap and good enough for "about 10,000 results".
+var TotalEstimated TotalMode = 2
+
+// TotalSkipped means that the number of entities isn't counted at all.
+var TotalSkipped TotalMode = 3
+
+// String returns a name of the mode.
+func (m TotalMode) String() string {
+	switch m {
+	case TotalExact:
+		return "exact"
+	case TotalEstimated:
+		return "estimated"
+	case TotalSkipped:
+		return "skipped"
+	}
+	return fmt.Sprintf("TotalMode(%d)", int(m))
+}
+
+// ErrInvalidPagination is returned by PaginationPolicy when the request Pagination breaks the policy.
+var ErrInvalidPagination = errors.New("invalid pagination")
+
 // Pagination keeps page by page processing information. This entity uses for requests as well as for responses.
 // In other words, it could be used to define the pagination properties whenever you send a request to the
 // target storage as well as using it from response for metrics/observation/further identical operation requests.
@@ -44,6 +78,15 @@ type Pagination struct {
 	// It doesn't have any value in request.
 	Total *uint64
 
+	// TotalMode asks for a kind of Total in requests. It's optional. If it isn't defined then the implementation
+	// default is taken. Implementations which can't estimate the number may count it exactly instead.
+	// It doesn't have any value in response, see TotalKind.
+	TotalMode *TotalMode
+
+	// TotalKind tells how Total was counted, i.e. TotalExact or TotalEstimated. It's defined in responses
+	// only together with Total.
+	TotalKind *TotalMode
+
 	// It determines whether there is a data after this page or not. It's optional.
 	// Some implementations may use it to improve user experience and efficiently handle further queries/requests.
 	// If it isn't specified then it should be treated as unknown.
@@ -52,3 +95,98 @@ type Pagination struct {
 	// for a response consumer.
 	HasNext *bool
 }
+
+// PaginationPolicy describes how an implementation interprets request Pagination. Implementations and decorators
+// should normalize every request Pagination by the same policy, so the priority rules are applied consistently.
+type PaginationPolicy struct {
+
+	// DefaultSize is a page size for requests without Size. It's required.
+	DefaultSize uint
+
+	// MaxSize is the greatest allowed page size. Larger sizes are reduced to it. It's optional. If it is 0 then
+	// the size isn't limited.
+	MaxSize uint
+
+	// AllowOffset defines whether requests could start from Offset. It's optional. By default, it's allowed.
+	AllowOffset *bool
+
+	// RequireTotal defines whether responses must report Total. See CheckResponse.
+	RequireTotal bool
+}
+
+// Normalize returns a copy of the request Pagination with the priority rules applied:
+//
+// - Size is set to DefaultSize if it isn't defined and it's reduced to MaxSize if it exceeds it. Size 0 is rejected.
+//
+// - If NextToken is defined then PrevToken and Offset are dropped.
+//
+// - Otherwise, if PrevToken is defined then Offset is dropped.
+//
+// - Otherwise, Offset is kept if it's allowed, a missing Offset is set to 0.
+//
+// TotalMode is kept, response only fields Total, TotalKind and HasNext are dropped. A nil Pagination is normalized
+// as an empty one. The request Pagination isn't changed. It returns an error wrapping ErrInvalidPagination if the request breaks the policy.
+func (p PaginationPolicy) Normalize(pagination *Pagination) (error, *Pagination) {
+	if p.DefaultSize == 0 {
+		return fmt.Errorf("%w: policy default size should be at least 1", ErrInvalidPagination), nil
+	}
+	if pagination == nil {
+		pagination = &Pagination{}
+	}
+
+	size := p.DefaultSize
+	if pagination.Size != nil {
+		size = *pagination.Size
+	}
+	if size == 0 {
+		return fmt.Errorf("%w: page size should be at least 1", ErrInvalidPagination), nil
+	}
+	if p.MaxSize > 0 && size > p.MaxSize {
+		size = p.MaxSize
+	}
+	normalized := &Pagination{Size: &size}
+	if pagination.TotalMode != nil {
+		switch mode := *pagination.TotalMode; mode {
+		case TotalExact, TotalEstimated, TotalSkipped:
+			if mode == TotalSkipped && p.RequireTotal {
+				return fmt.Errorf("%w: total is required", ErrInvalidPagination), nil
+			}
+			normalized.TotalMode = &mode
+		default:
+			return fmt.Errorf("%w: unknown total mode %d", ErrInvalidPagination, int(mode)), nil
+		}
+	}
+
+	switch {
+	case pagination.NextToken != nil:
+		if len(*pagination.NextToken) == 0 {
+			return fmt.Errorf("%w: next token is empty", ErrInvalidPagination), nil
+		}
+		token := append([]byte(nil), *pagination.NextToken...)
+		normalized.NextToken = &token
+	case pagination.PrevToken != nil:
+		if len(*pagination.PrevToken) == 0 {
+			return fmt.Errorf("%w: previous token is empty", ErrInvalidPagination), nil
+		}
+		token := append([]byte(nil), *pagination.PrevToken...)
+		normalized.PrevToken = &token
+	case pagination.Offset != nil && *pagination.Offset > 0:
+		if p.AllowOffset != nil && !*p.AllowOffset {
+			return fmt.Errorf("%w: offset isn't allowed, use page tokens", ErrInvalidPagination), nil
+		}
+		offset := *pagination.Offset
+		normalized.Offset = &offset
+	default:
+		var offset uint
+		normalized.Offset = &offset
+	}
+	return nil, normalized
+}
+
+// CheckResponse verifies that the response Pagination satisfies the policy, i.e. it has Total when it's required.
+func (p PaginationPolicy) CheckResponse(pagination *Pagination) error {
+	if p.RequireTotal && (pagination == nil || pagination.Total == nil) {
+		return errors.New("pagination policy requires total in response")
+	}
+	return nil
+}
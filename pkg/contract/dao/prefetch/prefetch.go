@@ -0,0 +1,328 @@
+package prefetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"iter"
+	"sync"
+	"time"
+)
+
+// DefaultDepth is a number of pages fetched ahead of processing when Config doesn't define Depth.
+const DefaultDepth uint = 2
+
+// Config is a configuration of Reader.
+type Config struct {
+
+	// Depth is a number of pages which are fetched ahead of processing. It bounds memory, at most Depth pages
+	// and Concurrency pages in flight are kept at once. It's optional. If it is 0 then DefaultDepth is taken.
+	Depth uint
+
+	// Concurrency is a number of pages requested at once. It's optional. If it is 0 or 1 then pages are requested
+	// one by one following NextToken, so fetching is only pipelined with processing. Otherwise, pages are addressed
+	// by Offset, so the backend must support offsets and the request must define Pagination.Size. If the backend
+	// returns another size for the first page then the next offsets follow it.
+	Concurrency uint
+}
+
+// Stats reports the progress of Reader.
+type Stats struct {
+
+	// Pages is a number of processed pages.
+	Pages uint64
+
+	// Items is a number of processed items.
+	Items uint64
+
+	// Fetch is a total duration of page requests. It could be greater than Elapsed for concurrent requests.
+	Fetch time.Duration
+
+	// Wait is a total duration the consumer waited for pages. If it's close to Elapsed then the backend is
+	// the bottleneck, otherwise the processing is.
+	Wait time.Duration
+
+	// Elapsed is a duration since the iteration start.
+	Elapsed time.Duration
+}
+
+// ItemsPerSecond returns a throughput of the processing.
+func (s Stats) ItemsPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Items) / s.Elapsed.Seconds()
+}
+
+// Reader reads every page of Read in background while the previous pages are processed. It's intended for bulk
+// exports where the processing would otherwise wait for every round trip. Stats is safe for concurrent use,
+// the iterators must not be used concurrently.
+type Reader[T any] struct {
+	read    func(ctx context.Context, pagination *dao.Pagination) (error, *dao.ReadResponse[T])
+	first   dao.Pagination
+	depth   uint
+	workers uint
+
+	mu      sync.Mutex
+	stats   Stats
+	started time.Time
+}
+
+type result[T any] struct {
+	response *dao.ReadResponse[T]
+	err      error
+	last     bool
+}
+
+// New creates a Reader of the request pages. The request isn't changed. It returns an error if the configuration
+// requires offsets and the request doesn't define a page size.
+func New[K any, T any, F any](d dao.DAO[K, T, F], request *dao.ReadRequest[F], cfg Config) (error, *Reader[T]) {
+	r := dao.ReadRequest[F]{}
+	if request != nil {
+		r = *request
+	}
+	reader := &Reader[T]{
+		read: func(ctx context.Context, pagination *dao.Pagination) (error, *dao.ReadResponse[T]) {
+			page := r
+			page.Pagination = pagination
+			return d.Read(ctx, &page)
+		},
+		depth:   cfg.Depth,
+		workers: max(cfg.Concurrency, 1),
+	}
+	if r.Pagination != nil {
+		reader.first = *r.Pagination
+	}
+	if reader.depth == 0 {
+		reader.depth = DefaultDepth
+	}
+	if reader.workers > 1 && (reader.first.Size == nil || *reader.first.Size == 0) {
+		return errors.New("concurrent prefetching requires pagination size"), nil
+	}
+	return nil, reader
+}
+
+// Stats returns the progress of the current or the last iteration.
+func (r *Reader[T]) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats
+	if !r.started.IsZero() {
+		stats.Elapsed = time.Since(r.started)
+	}
+	return stats
+}
+
+// All returns an iterator over every item. See Pages for the details.
+func (r *Reader[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for response, err := range r.Pages(ctx) {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range response.Data {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Pages returns an iterator over every page in order. The pages are fetched in background, the fetching stops
+// when the iteration is stopped or the context is canceled. The first error stops the iteration and it's yielded
+// with nil page. Every iteration starts from the request pagination and resets Stats.
+func (r *Reader[T]) Pages(ctx context.Context) iter.Seq2[*dao.ReadResponse[T], error] {
+	return func(yield func(*dao.ReadResponse[T], error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+
+		r.mu.Lock()
+		r.stats = Stats{}
+		r.started = time.Now()
+		r.mu.Unlock()
+
+		var results <-chan chan result[T]
+		if r.workers > 1 {
+			results = r.concurrent(ctx, &wg)
+		} else {
+			results = r.sequential(ctx, &wg)
+		}
+
+		for {
+			waitStart := time.Now()
+			var res result[T]
+			select {
+			case <-ctx.Done():
+				res.err = ctx.Err()
+			case slot, ok := <-results:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					res.err = ctx.Err()
+				case res = <-slot:
+				}
+			}
+
+			r.mu.Lock()
+			r.stats.Wait += time.Since(waitStart)
+			if res.err == nil {
+				r.stats.Pages++
+				r.stats.Items += uint64(len(res.response.Data))
+			}
+			r.mu.Unlock()
+
+			if res.err != nil {
+				yield(nil, res.err)
+				return
+			}
+			if !yield(res.response, nil) || res.last {
+				return
+			}
+		}
+	}
+}
+
+// sequential fetches pages one by one following NextToken. Every page is sent as soon as it's fetched, at most
+// depth pages wait for processing.
+func (r *Reader[T]) sequential(ctx context.Context, wg *sync.WaitGroup) <-chan chan result[T] {
+	results := make(chan chan result[T], r.depth)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(results)
+
+		pagination := r.first
+		for {
+			err, response := r.fetch(ctx, &pagination)
+			end := err == nil && last(response, nil, 1)
+			slot := make(chan result[T], 1)
+			slot <- result[T]{response: response, err: err, last: end}
+			select {
+			case <-ctx.Done():
+				return
+			case results <- slot:
+			}
+			if err != nil || end {
+				return
+			}
+			token := append([]byte(nil), *response.Pagination.NextToken...)
+			pagination = dao.Pagination{Size: r.first.Size, NextToken: &token, TotalMode: r.first.TotalMode}
+		}
+	}()
+	return results
+}
+
+// concurrent requests pages by offsets with the bounded number of workers. The slots are sent in the page order,
+// so the consumer receives pages in order while they're fetched concurrently. The first page is fetched alone,
+// since the backend could clamp the requested size and the offsets of the next pages follow the size it returns.
+func (r *Reader[T]) concurrent(ctx context.Context, wg *sync.WaitGroup) <-chan chan result[T] {
+	results := make(chan chan result[T], r.depth)
+	workers := make(chan struct{}, r.workers)
+
+	var done sync.Once
+	finished := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(results)
+
+		size := *r.first.Size
+		var start uint
+		if r.first.Offset != nil {
+			start = *r.first.Offset
+		}
+
+		err, response := r.fetch(ctx, &dao.Pagination{Size: &size, Offset: &start, TotalMode: r.first.TotalMode})
+		if err == nil && response.Pagination != nil && response.Pagination.Size != nil && *response.Pagination.Size > 0 {
+			size = *response.Pagination.Size
+		}
+		end := err == nil && last(response, &size, r.workers)
+		slot := make(chan result[T], 1)
+		slot <- result[T]{response: response, err: err, last: end}
+		select {
+		case <-ctx.Done():
+			return
+		case results <- slot:
+		}
+		if err != nil || end {
+			return
+		}
+
+		for page := uint(1); ; page++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-finished:
+				return
+			case workers <- struct{}{}:
+			}
+
+			slot := make(chan result[T], 1)
+			select {
+			case <-ctx.Done():
+				<-workers
+				return
+			case <-finished:
+				<-workers
+				return
+			case results <- slot:
+			}
+
+			offset := start + page*size
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-workers }()
+
+				pagination := &dao.Pagination{Size: &size, Offset: &offset, TotalMode: r.first.TotalMode}
+				err, response := r.fetch(ctx, pagination)
+				if err == nil && response.Pagination != nil && response.Pagination.Size != nil &&
+					*response.Pagination.Size != size {
+					err = fmt.Errorf("page size changed from %d to %d at offset %d", size, *response.Pagination.Size, offset)
+				}
+				end := err == nil && last(response, &size, r.workers)
+				if err != nil || end {
+					done.Do(func() { close(finished) })
+				}
+				slot <- result[T]{response: response, err: err, last: end}
+			}()
+		}
+	}()
+	return results
+}
+
+func (r *Reader[T]) fetch(ctx context.Context, pagination *dao.Pagination) (error, *dao.ReadResponse[T]) {
+	fetchStart := time.Now()
+	err, response := r.read(ctx, pagination)
+	if err == nil && response == nil {
+		response = &dao.ReadResponse[T]{}
+	}
+
+	r.mu.Lock()
+	r.stats.Fetch += time.Since(fetchStart)
+	r.mu.Unlock()
+	return err, response
+}
+
+// last reports whether the page is the last one. Token based pages end without a next token, offset based pages
+// end with a short page unless HasNext is true. Both end when HasNext is false.
+func last[T any](response *dao.ReadResponse[T], size *uint, workers uint) bool {
+	p := response.Pagination
+	if p != nil && p.HasNext != nil && !*p.HasNext {
+		return true
+	}
+	if workers > 1 {
+		return (p == nil || p.HasNext == nil) && uint(len(response.Data)) < *size
+	}
+	return p == nil || p.NextToken == nil || len(*p.NextToken) == 0
+}
@@ -18,10 +18,14 @@ type singletonDAOFactory[K any, T any, F any] struct {
 	mutex    sync.Mutex
 }
 
+// NewSingletonDAOFactory creates a factory which keeps the first made DAO and returns it for every next call
+// whatever the name is. Use NewKeyedDAOFactory to get a DAO per name.
 func NewSingletonDAOFactory[K any, T any, F any](ctx context.Context) DAOFactory[K, T, F] {
 	return &singletonDAOFactory[K, T, F]{}
 }
 
+// Make resolves DAO and config producer by name through the registry.Scope carried by the context, or through
+// the package-level registries if there is no scope, configures the DAO and keeps it for the next calls.
 func (s *singletonDAOFactory[K, T, F]) Make(ctx context.Context, name string) (error, *dao.DAO[K, T, F]) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -30,12 +34,12 @@ func (s *singletonDAOFactory[K, T, F]) Make(ctx context.Context, name string) (e
 		return nil, s.instance
 	}
 
-	err, producer := config.GetConfigProducer(name)
+	err, producer := config.GetConfigProducerFromContext(ctx, name)
 	if err != nil {
 		return err, nil
 	}
 
-	err, targetDao := dao.GetDAO[dao.DAO[K, T, F]](name)
+	err, targetDao := dao.GetDAOFromContext[dao.DAO[K, T, F]](ctx, name)
 	if err != nil {
 		return err, nil
 	}
@@ -0,0 +1,130 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"sync"
+)
+
+// KeyedDAOFactory configures and caches one DAO per registry name of every registry.Scope.
+type KeyedDAOFactory[K any, T any, F any] interface {
+	DAOFactory[K, T, F]
+
+	// Invalidate drops the cached DAOs of the name in every scope, so the next Make resolves and configures them
+	// again. It doesn't close the DAOs since the instances belong to the registry.
+	Invalidate(name string)
+
+	// InvalidateAll drops every cached DAO.
+	InvalidateAll()
+
+	// InvalidateScope drops every cached DAO of the scope and forgets the scope. It's intended for scopes which
+	// aren't used anymore, e.g. per test scopes, so they don't pile up in the factory.
+	InvalidateScope(scope *registry.Scope)
+}
+
+type keyedEntry[K any, T any, F any] struct {
+	instance *dao.DAO[K, T, F]
+	mutex    sync.Mutex
+}
+
+type keyedKey struct {
+	scope *registry.Scope
+	name  string
+}
+
+type keyedDAOFactory[K any, T any, F any] struct {
+	entries map[keyedKey]*keyedEntry[K, T, F]
+	mutex   sync.Mutex
+}
+
+func NewKeyedDAOFactory[K any, T any, F any](ctx context.Context) KeyedDAOFactory[K, T, F] {
+	return &keyedDAOFactory[K, T, F]{
+		entries: map[keyedKey]*keyedEntry[K, T, F]{},
+	}
+}
+
+// Make returns the cached DAO of the name in the registry.Scope carried by the context or resolves it like
+// the singleton factory does. Every name has its own lock, so a slow Configure of one backend doesn't block
+// the others. A failed Make isn't cached.
+func (k *keyedDAOFactory[K, T, F]) Make(ctx context.Context, name string) (error, *dao.DAO[K, T, F]) {
+	key := keyedKey{scope: registry.ScopeFrom(ctx), name: name}
+	k.mutex.Lock()
+	entry, ok := k.entries[key]
+	if !ok {
+		entry = &keyedEntry[K, T, F]{}
+		k.entries[key] = entry
+	}
+	k.mutex.Unlock()
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	if entry.instance != nil {
+		return nil, entry.instance
+	}
+
+	err, producer := config.GetConfigProducerFromContext(ctx, name)
+	if err != nil {
+		return err, nil
+	}
+
+	err, targetDao := dao.GetDAOFromContext[dao.DAO[K, T, F]](ctx, name)
+	if err != nil {
+		return err, nil
+	}
+
+	if targetDao == nil {
+		return errors.New(name + " dao wasn't found"), nil
+	}
+
+	err = (*targetDao).Configure(ctx, (*producer)(ctx))
+	if err != nil {
+		return err, nil
+	}
+
+	entry.instance = targetDao
+
+	return nil, entry.instance
+}
+
+// Invalidate clears the cached DAOs under their own locks, so a concurrent Make of the name waits for Configure
+// in progress instead of configuring the same DAO at once.
+func (k *keyedDAOFactory[K, T, F]) Invalidate(name string) {
+	k.clear(func(key keyedKey) bool { return key.name == name })
+}
+
+func (k *keyedDAOFactory[K, T, F]) InvalidateAll() {
+	k.clear(func(key keyedKey) bool { return true })
+}
+
+func (k *keyedDAOFactory[K, T, F]) InvalidateScope(scope *registry.Scope) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	for key := range k.entries {
+		if key.scope == scope {
+			delete(k.entries, key)
+		}
+	}
+}
+
+// clear drops cached DAOs of the matched entries. The entries are kept, so their locks still serialize Make calls.
+func (k *keyedDAOFactory[K, T, F]) clear(match func(key keyedKey) bool) {
+	k.mutex.Lock()
+	entries := make([]*keyedEntry[K, T, F], 0)
+	for key, entry := range k.entries {
+		if match(key) {
+			entries = append(entries, entry)
+		}
+	}
+	k.mutex.Unlock()
+
+	for _, entry := range entries {
+		entry.mutex.Lock()
+		entry.instance = nil
+		entry.mutex.Unlock()
+	}
+}
@@ -0,0 +1,259 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"sync"
+	"time"
+)
+
+// DefaultPoolSize is a maximum number of DAOs per name when PoolConfig doesn't define MaxSize.
+const DefaultPoolSize uint = 10
+
+// ErrPoolClosed is returned by Acquire after the pool has been closed.
+var ErrPoolClosed = errors.New("dao pool is closed")
+
+// PoolConfig is a configuration of the pooled factory.
+type PoolConfig[K any, T any, F any] struct {
+
+	// MaxSize is a maximum number of DAOs per name, lent and idle ones together. Acquire waits when all of them are
+	// lent. It's optional. If it is 0 then DefaultPoolSize is taken.
+	MaxSize uint
+
+	// IdleTimeout is a duration after which an idle DAO is closed and evicted. Expired DAOs are evicted by the pool
+	// calls and by a background check every IdleTimeout, so an unused pool doesn't keep them either. It's optional.
+	// If it is 0 then idle DAOs are kept until the pool is closed.
+	IdleTimeout time.Duration
+
+	// HealthCheck verifies an idle DAO before it's lent. An unhealthy DAO is closed and replaced by a new one.
+	// It's optional.
+	HealthCheck func(ctx context.Context, d dao.DAO[K, T, F]) error
+}
+
+// PooledDAOFactory lends configured DAOs which must be returned by Release. Every lent DAO is used by one borrower
+// at once, so it suits backends with non-thread-safe client handles. The DAOs must be registered by
+// dao.RegisterProvider or dao.RegisterScopedProvider, the pool builds them by factory prototypes.
+type PooledDAOFactory[K any, T any, F any] interface {
+
+	// Make is the same as Acquire. The DAO must be returned by Release.
+	DAOFactory[K, T, F]
+
+	// Acquire lends a DAO of the name. It takes an idle DAO which passes the health check or builds a new one.
+	// It waits for a released DAO if MaxSize DAOs of the name are lent until the context is done.
+	Acquire(ctx context.Context, name string) (error, *dao.DAO[K, T, F])
+
+	// Release returns the DAO lent by Acquire into the pool. It returns error if the DAO wasn't lent by the pool.
+	Release(d *dao.DAO[K, T, F]) error
+
+	// Close closes idle DAOs and makes the pool refuse new Acquire calls. Lent DAOs are closed when released.
+	Close() error
+}
+
+type pooledDAO[K any, T any, F any] struct {
+	instance dao.DAO[K, T, F]
+	pool     *daoPool[K, T, F]
+	idleFrom time.Time
+}
+
+// daoPool keeps DAOs of one name. A slot is taken by every borrower, so the number of lent DAOs never exceeds
+// the size. A borrower reuses an idle DAO before building a new one, so the number of all DAOs doesn't either.
+type daoPool[K any, T any, F any] struct {
+	slots chan struct{}
+	idle  []*pooledDAO[K, T, F]
+}
+
+type pooledDAOFactory[K any, T any, F any] struct {
+	config PoolConfig[K, T, F]
+	pools  map[string]*daoPool[K, T, F]
+	lent   map[*dao.DAO[K, T, F]]*pooledDAO[K, T, F]
+	closed bool
+	stop   chan struct{}
+	mutex  sync.Mutex
+}
+
+func NewPooledDAOFactory[K any, T any, F any](ctx context.Context, config PoolConfig[K, T, F]) PooledDAOFactory[K, T, F] {
+	if config.MaxSize == 0 {
+		config.MaxSize = DefaultPoolSize
+	}
+	p := &pooledDAOFactory[K, T, F]{
+		config: config,
+		pools:  map[string]*daoPool[K, T, F]{},
+		lent:   map[*dao.DAO[K, T, F]]*pooledDAO[K, T, F]{},
+		stop:   make(chan struct{}),
+	}
+	if config.IdleTimeout > 0 {
+		go p.janitor()
+	}
+	return p
+}
+
+// janitor evicts expired idle DAOs of every name until the pool is closed.
+func (p *pooledDAOFactory[K, T, F]) janitor() {
+	ticker := time.NewTicker(p.config.IdleTimeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		p.mutex.Lock()
+		expired := make([]*pooledDAO[K, T, F], 0)
+		for _, pool := range p.pools {
+			expired = append(expired, p.evict(pool)...)
+		}
+		p.mutex.Unlock()
+		_ = closeAll(expired)
+	}
+}
+
+func (p *pooledDAOFactory[K, T, F]) Make(ctx context.Context, name string) (error, *dao.DAO[K, T, F]) {
+	return p.Acquire(ctx, name)
+}
+
+func (p *pooledDAOFactory[K, T, F]) Acquire(ctx context.Context, name string) (error, *dao.DAO[K, T, F]) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return ErrPoolClosed, nil
+	}
+	pool, ok := p.pools[name]
+	if !ok {
+		pool = &daoPool[K, T, F]{slots: make(chan struct{}, p.config.MaxSize)}
+		p.pools[name] = pool
+	}
+	p.mutex.Unlock()
+
+	select {
+	case pool.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err(), nil
+	}
+
+	err, item := p.borrow(ctx, name, pool)
+	if err != nil {
+		<-pool.slots
+		return err, nil
+	}
+	return nil, &item.instance
+}
+
+// borrow takes a healthy idle DAO or builds a new one. The caller holds a slot of the pool.
+func (p *pooledDAOFactory[K, T, F]) borrow(ctx context.Context, name string, pool *daoPool[K, T, F]) (error, *pooledDAO[K, T, F]) {
+	for {
+		p.mutex.Lock()
+		if p.closed {
+			p.mutex.Unlock()
+			return ErrPoolClosed, nil
+		}
+		expired := p.evict(pool)
+		var item *pooledDAO[K, T, F]
+		if n := len(pool.idle); n > 0 {
+			item, pool.idle = pool.idle[n-1], pool.idle[:n-1]
+			p.lent[&item.instance] = item
+		}
+		p.mutex.Unlock()
+		closeAll(expired)
+
+		if item == nil {
+			break
+		}
+		if p.config.HealthCheck == nil || p.config.HealthCheck(ctx, item.instance) == nil {
+			return nil, item
+		}
+
+		p.mutex.Lock()
+		delete(p.lent, &item.instance)
+		p.mutex.Unlock()
+		_ = item.instance.Close()
+	}
+
+	err, d := build[K, T, F](ctx, name)
+	if err != nil {
+		return err, nil
+	}
+	item := &pooledDAO[K, T, F]{instance: *d, pool: pool}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		_ = item.instance.Close()
+		return ErrPoolClosed, nil
+	}
+	p.lent[&item.instance] = item
+	return nil, item
+}
+
+func (p *pooledDAOFactory[K, T, F]) Release(d *dao.DAO[K, T, F]) error {
+	p.mutex.Lock()
+	item, ok := p.lent[d]
+	if !ok {
+		p.mutex.Unlock()
+		return errors.New("dao wasn't acquired from the pool")
+	}
+	delete(p.lent, d)
+
+	closed := p.closed
+	if !closed {
+		item.idleFrom = time.Now()
+		item.pool.idle = append(item.pool.idle, item)
+	}
+	expired := p.evict(item.pool)
+	p.mutex.Unlock()
+
+	<-item.pool.slots
+	closeAll(expired)
+	if closed {
+		return item.instance.Close()
+	}
+	return nil
+}
+
+func (p *pooledDAOFactory[K, T, F]) Close() error {
+	p.mutex.Lock()
+	if !p.closed {
+		close(p.stop)
+	}
+	p.closed = true
+	idle := make([]*pooledDAO[K, T, F], 0)
+	for _, pool := range p.pools {
+		idle = append(idle, pool.idle...)
+		pool.idle = nil
+	}
+	p.mutex.Unlock()
+
+	return closeAll(idle)
+}
+
+// evict removes idle DAOs which have been idle longer than IdleTimeout and returns them to be closed outside
+// the lock. It must be called under the lock.
+func (p *pooledDAOFactory[K, T, F]) evict(pool *daoPool[K, T, F]) []*pooledDAO[K, T, F] {
+	if p.config.IdleTimeout <= 0 {
+		return nil
+	}
+	deadline := time.Now().Add(-p.config.IdleTimeout)
+	kept := pool.idle[:0]
+	expired := make([]*pooledDAO[K, T, F], 0)
+	for _, item := range pool.idle {
+		if item.idleFrom.Before(deadline) {
+			expired = append(expired, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	pool.idle = kept
+	return expired
+}
+
+func closeAll[K any, T any, F any](items []*pooledDAO[K, T, F]) error {
+	errs := make([]error, 0)
+	for _, item := range items {
+		if err := item.instance.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,40 @@
+package factory
+
+import (
+	"context"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+)
+
+type prototypeDAOFactory[K any, T any, F any] struct {
+}
+
+// NewPrototypeDAOFactory creates a factory which builds and configures a new DAO on every Make. The DAO must be
+// registered by dao.RegisterProvider or dao.RegisterScopedProvider. The caller owns the made DAO and should close it.
+func NewPrototypeDAOFactory[K any, T any, F any](ctx context.Context) DAOFactory[K, T, F] {
+	return &prototypeDAOFactory[K, T, F]{}
+}
+
+func (p *prototypeDAOFactory[K, T, F]) Make(ctx context.Context, name string) (error, *dao.DAO[K, T, F]) {
+	return build[K, T, F](ctx, name)
+}
+
+// build creates a new DAO by its provider and configures it. The DAO is closed if it can't be configured.
+func build[K any, T any, F any](ctx context.Context, name string) (error, *dao.DAO[K, T, F]) {
+	err, producer := config.GetConfigProducerFromContext(ctx, name)
+	if err != nil {
+		return err, nil
+	}
+
+	err, targetDao := dao.NewDAOFromContext[dao.DAO[K, T, F]](ctx, name)
+	if err != nil {
+		return err, nil
+	}
+
+	err = (*targetDao).Configure(ctx, (*producer)(ctx))
+	if err != nil {
+		_ = (*targetDao).Close()
+		return err, nil
+	}
+	return nil, targetDao
+}
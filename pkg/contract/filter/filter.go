@@ -1,7 +1,10 @@
 package filter
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 )
 
@@ -111,28 +114,146 @@ var RegEx Operation = 8
 // Between is a range expression. Usually, it uses together with a range of values.
 var Between Operation = 9
 
-var ops = []Operation{Undefined, Eq, Contains, StartWith, Gt, Gte, Lt, Lte, RegEx, Between}
+// Ne is not equals. A nil value means that the field isn't null.
+var Ne Operation = 10
+
+// In is checking out whether the field is equal to any value of the list. The value is a slice or an array,
+// e.g. []string{"a", "b"}.
+var In Operation = 11
+
+// NotIn is checking out whether the field isn't equal to every value of the list. The value is a slice or an array.
+var NotIn Operation = 12
+
+// IsNull is checking out whether the field is null, e.g. nil pointer. It has no value.
+var IsNull Operation = 13
+
+// Exists is checking out whether the field is present in the entity. It has no value. Storages without optional
+// fields, e.g. SQL tables, treat it as the field isn't null.
+var Exists Operation = 14
+
+// EndsWith is checking out whether the value is ended with some suffix. It is used on string based fields.
+var EndsWith Operation = 15
+
+// EqIgnoreCase is equals of strings under Unicode case-folding.
+var EqIgnoreCase Operation = 16
+
+// ContainsIgnoreCase is Contains of strings under Unicode case-folding.
+var ContainsIgnoreCase Operation = 17
+
+// StartWithIgnoreCase is StartWith of strings under Unicode case-folding.
+var StartWithIgnoreCase Operation = 18
+
+// EndsWithIgnoreCase is EndsWith of strings under Unicode case-folding.
+var EndsWithIgnoreCase Operation = 19
+
+// Arity describes a shape of the expression value that an operation expects.
+type Arity int
+
+// Nullary operation has no value, e.g. Undefined.
+var Nullary Arity = 0
+
+// Unary operation has a single value, e.g. Eq or Gt.
+var Unary Arity = 1
+
+// Binary operation has a range of exactly two values, e.g. Between.
+var Binary Arity = 2
+
+// Variadic operation has a list of values.
+var Variadic Arity = 3
+
+type operationInfo struct {
+	name  string
+	arity Arity
+}
+
+var ops = []Operation{
+	Undefined, Eq, Contains, StartWith, Gt, Gte, Lt, Lte, RegEx, Between,
+	Ne, In, NotIn, IsNull, Exists, EndsWith, EqIgnoreCase, ContainsIgnoreCase, StartWithIgnoreCase, EndsWithIgnoreCase,
+}
+var opsInfo = map[Operation]operationInfo{
+	Undefined: {"undefined", Nullary},
+	Eq:        {"eq", Unary},
+	Contains:  {"contains", Unary},
+	StartWith: {"startWith", Unary},
+	Gt:        {"gt", Unary},
+	Gte:       {"gte", Unary},
+	Lt:        {"lt", Unary},
+	Lte:       {"lte", Unary},
+	RegEx:     {"regex", Unary},
+	Between:   {"between", Binary},
+
+	Ne:                  {"ne", Unary},
+	In:                  {"in", Variadic},
+	NotIn:               {"notIn", Variadic},
+	IsNull:              {"isNull", Nullary},
+	Exists:              {"exists", Nullary},
+	EndsWith:            {"endsWith", Unary},
+	EqIgnoreCase:        {"eqIgnoreCase", Unary},
+	ContainsIgnoreCase:  {"containsIgnoreCase", Unary},
+	StartWithIgnoreCase: {"startWithIgnoreCase", Unary},
+	EndsWithIgnoreCase:  {"endsWithIgnoreCase", Unary},
+}
 var opsMap = map[int]Operation{}
-var mu sync.Mutex
-var opsInz = false
+var namesMap = map[string]Operation{}
+var mu sync.RWMutex
+var opsInz sync.Once
 
 func initOps() {
-	mu.Lock()
-	defer mu.Unlock()
-	if !opsInz {
+	opsInz.Do(func() {
+		mu.Lock()
+		defer mu.Unlock()
 		for _, op := range ops {
 			opsMap[FromOperation(op)] = op
+			namesMap[opsInfo[op].name] = op
+		}
+	})
+}
+
+// RegisterOperation registers a custom Operation, so it could be converted by ToOperation and ParseOperation and
+// carried by filters the same way as built-in operations. The arity is optional, Unary is used by default.
+// It returns error if the code or the name is already taken, e.g. by a built-in operation.
+//
+// Evaluators and translators don't know how to handle custom operations, check it by Supporter before using them.
+func RegisterOperation(code int, name string, arity ...Arity) (error, Operation) {
+	initOps()
+	if name == "" {
+		return errors.New("operation name is empty"), Undefined
+	}
+	if len(arity) > 1 {
+		return fmt.Errorf("operation %s has more than one arity", name), Undefined
+	}
+	info := operationInfo{name: name, arity: Unary}
+	if len(arity) == 1 {
+		if arity[0] < Nullary || arity[0] > Variadic {
+			return fmt.Errorf("operation %s has unknown arity %d", name, arity[0]), Undefined
 		}
-		opsInz = true
+		info.arity = arity[0]
 	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if existing, ok := opsMap[code]; ok {
+		return fmt.Errorf("operation code %d is already registered for %s", code, opsInfo[existing].name), Undefined
+	}
+	if _, ok := namesMap[name]; ok {
+		return fmt.Errorf("operation name %s is already registered", name), Undefined
+	}
+
+	op := Operation(code)
+	opsMap[code] = op
+	namesMap[name] = op
+	opsInfo[op] = info
+	return nil, op
 }
 
 // ToOperation converts `int` data type representation of the operation into Operation instance. If there is no such
 // Operation for the input `int` value then error will be returned.
 func ToOperation(op int) (error, Operation) {
-	if !opsInz {
-		initOps()
-	}
+	initOps()
+	mu.RLock()
+	defer mu.RUnlock()
+
 	if op, ok := opsMap[op]; ok {
 		return nil, op
 	}
@@ -143,3 +264,109 @@ func ToOperation(op int) (error, Operation) {
 func FromOperation(op Operation) int {
 	return int(op)
 }
+
+// ParseOperation converts the name of the operation returned by Operation.String into Operation instance. If there is
+// no such Operation for the name then error will be returned.
+func ParseOperation(name string) (error, Operation) {
+	initOps()
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if op, ok := namesMap[name]; ok {
+		return nil, op
+	}
+	return fmt.Errorf("there is no operation for name %q", name), Undefined
+}
+
+// String returns a stable name of the operation, e.g. "eq" or "startWith". Unregistered operations are represented
+// by their code.
+func (o Operation) String() string {
+	if info, ok := o.info(); ok {
+		return info.name
+	}
+	return fmt.Sprintf("operation(%d)", FromOperation(o))
+}
+
+// Registered reports whether the operation is built-in or registered by RegisterOperation.
+func (o Operation) Registered() bool {
+	_, ok := o.info()
+	return ok
+}
+
+// Arity returns a shape of the value that the operation expects. It returns Unary for unregistered operations.
+func (o Operation) Arity() Arity {
+	if info, ok := o.info(); ok {
+		return info.arity
+	}
+	return Unary
+}
+
+func (o Operation) info() (operationInfo, bool) {
+	initOps()
+	mu.RLock()
+	defer mu.RUnlock()
+
+	info, ok := opsInfo[o]
+	return info, ok
+}
+
+// BuiltinOperations returns the built-in operations except Undefined. Match and the translators of this module
+// support exactly them.
+func BuiltinOperations() []Operation {
+	return slices.Clone(ops[1:])
+}
+
+// IsBuiltin reports whether the operation is a built-in one. Undefined isn't.
+func IsBuiltin(op Operation) bool {
+	return op != Undefined && slices.Contains(ops, op)
+}
+
+// Supporter is implemented by evaluators and translators of filters. It allows to check whether a filter could be
+// handled before it's used, e.g. when it contains custom operations.
+type Supporter interface {
+
+	// Supports reports whether the operation could be handled.
+	Supports(op Operation) bool
+}
+
+// SupporterFunc is an adapter to use ordinary functions as Supporter.
+type SupporterFunc func(op Operation) bool
+
+// Supports calls s(op).
+func (s SupporterFunc) Supports(op Operation) bool {
+	return s(op)
+}
+
+// CheckSupported walks through the filter tree and returns error listing all operations that the supporter
+// can't handle. It returns nil if the whole filter is supported.
+func CheckSupported(f Filter, s Supporter) error {
+	if f == nil {
+		return nil
+	}
+	unsupported := make([]string, 0)
+	seen := map[Operation]bool{}
+	var walk func(f Filter)
+	walk = func(f Filter) {
+		for _, fe := range f.GetFields() {
+			if fe.Expression() == nil {
+				continue
+			}
+			op := fe.Expression().Op()
+			if !seen[op] && !s.Supports(op) {
+				unsupported = append(unsupported, op.String())
+			}
+			seen[op] = true
+		}
+		for _, group := range [][]Filter{f.GetAnd(), f.GetOr(), f.GetNot()} {
+			for _, sub := range group {
+				walk(sub)
+			}
+		}
+	}
+	walk(f)
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("unsupported operations: %s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
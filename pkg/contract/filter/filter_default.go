@@ -48,3 +48,73 @@ func NewExpression(op Operation, value any) Expression {
 }
 
 //===========================================================================
+
+type simpleFilter struct {
+	and    []Filter
+	or     []Filter
+	not    []Filter
+	fields []FieldExpression
+}
+
+func (s *simpleFilter) And(f ...Filter) Filter {
+	s.and = appendNonNil(s.and, f)
+	return s
+}
+
+func (s *simpleFilter) Or(f ...Filter) Filter {
+	s.or = appendNonNil(s.or, f)
+	return s
+}
+
+func (s *simpleFilter) Not(f ...Filter) Filter {
+	s.not = appendNonNil(s.not, f)
+	return s
+}
+
+func (s *simpleFilter) AddField(fe ...FieldExpression) Filter {
+	for _, e := range fe {
+		if e != nil {
+			s.fields = append(s.fields, e)
+		}
+	}
+	return s
+}
+
+func (s *simpleFilter) GetAnd() []Filter {
+	return append(make([]Filter, 0, len(s.and)), s.and...)
+}
+
+func (s *simpleFilter) GetOr() []Filter {
+	return append(make([]Filter, 0, len(s.or)), s.or...)
+}
+
+func (s *simpleFilter) GetNot() []Filter {
+	return append(make([]Filter, 0, len(s.not)), s.not...)
+}
+
+func (s *simpleFilter) GetFields() []FieldExpression {
+	return append(make([]FieldExpression, 0, len(s.fields)), s.fields...)
+}
+
+// New creates a default Filter implementation. Every builder call returns the same instance, so the tree could be
+// composed fluently. Nil filters and field expressions are skipped. The getters return copies, so the returned
+// slices could be modified without affecting the filter.
+func New() Filter {
+	return &simpleFilter{
+		and:    make([]Filter, 0),
+		or:     make([]Filter, 0),
+		not:    make([]Filter, 0),
+		fields: make([]FieldExpression, 0),
+	}
+}
+
+func appendNonNil(dst []Filter, src []Filter) []Filter {
+	for _, f := range src {
+		if f != nil {
+			dst = append(dst, f)
+		}
+	}
+	return dst
+}
+
+//===========================================================================
@@ -0,0 +1,442 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// JSONVersion is a version of the JSON wire format produced by MarshalJSON.
+//
+// The document of version 1 looks like next:
+//
+//	{
+//	  "version": 1,
+//	  "filter": {
+//	    "fields": [
+//	      {"name": "status", "op": "eq", "code": 1, "value": {"type": "string", "value": "active"}},
+//	      {"name": "age", "op": "between", "code": 9, "value": {"type": "list", "elem": "int", "value": [18, 30]}}
+//	    ],
+//	    "and": [ <filter>, ... ],
+//	    "or":  [ <filter>, ... ],
+//	    "not": [ <filter>, ... ]
+//	  }
+//	}
+//
+// A field carries the operation by its name ("op") and its code ("code"). Decoder accepts either of them, if both
+// are present then they must refer to the same operation. A value without "value" key is nil.
+//
+// Value types are: "string", "bool", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32",
+// "uint64", "float32", "float64", "time" (RFC 3339 string with nanoseconds), "duration" (time.Duration string) and
+// "list". A list with "elem" of a scalar type holds bare JSON values and decodes into a typed slice, e.g. []int.
+// A list with "elem": "any" holds typed values and decodes into []any. Named types are encoded by their underlying
+// kind, *regexp.Regexp is encoded as a string.
+const JSONVersion = 1
+
+type jsonDocument struct {
+	Version int         `json:"version"`
+	Filter  *jsonFilter `json:"filter"`
+}
+
+type jsonFilter struct {
+	Fields []*jsonField  `json:"fields,omitempty"`
+	And    []*jsonFilter `json:"and,omitempty"`
+	Or     []*jsonFilter `json:"or,omitempty"`
+	Not    []*jsonFilter `json:"not,omitempty"`
+}
+
+type jsonField struct {
+	Name  string     `json:"name"`
+	Op    string     `json:"op,omitempty"`
+	Code  *int       `json:"code,omitempty"`
+	Value *jsonValue `json:"value,omitempty"`
+}
+
+type jsonValue struct {
+	Type  string          `json:"type"`
+	Elem  string          `json:"elem,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+const (
+	listType     = "list"
+	anyType      = "any"
+	timeName     = "time"
+	durationName = "duration"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var scalarTypes = map[string]reflect.Type{
+	"string":     reflect.TypeOf(""),
+	"bool":       reflect.TypeOf(false),
+	"int":        reflect.TypeOf(0),
+	"int8":       reflect.TypeOf(int8(0)),
+	"int16":      reflect.TypeOf(int16(0)),
+	"int32":      reflect.TypeOf(int32(0)),
+	"int64":      reflect.TypeOf(int64(0)),
+	"uint":       reflect.TypeOf(uint(0)),
+	"uint8":      reflect.TypeOf(uint8(0)),
+	"uint16":     reflect.TypeOf(uint16(0)),
+	"uint32":     reflect.TypeOf(uint32(0)),
+	"uint64":     reflect.TypeOf(uint64(0)),
+	"float32":    reflect.TypeOf(float32(0)),
+	"float64":    reflect.TypeOf(float64(0)),
+	timeName:     timeType,
+	durationName: durationType,
+}
+
+// MarshalJSON encodes the filter into JSON wire format of JSONVersion. It returns error if some value has a type
+// that the format doesn't support.
+func MarshalJSON(f Filter) (error, []byte) {
+	doc := jsonDocument{Version: JSONVersion}
+	if f != nil {
+		err, jf := encodeFilter(f)
+		if err != nil {
+			return err, nil
+		}
+		doc.Filter = jf
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err, nil
+	}
+	return nil, data
+}
+
+// UnmarshalJSON decodes the filter from JSON wire format. The result is built by New, NewFieldExpression and
+// NewExpression. It returns nil filter if the document has no filter.
+func UnmarshalJSON(data []byte) (error, Filter) {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err, nil
+	}
+	if doc.Version != JSONVersion {
+		return fmt.Errorf("unsupported filter format version %d", doc.Version), nil
+	}
+	if doc.Filter == nil {
+		return nil, nil
+	}
+	return decodeFilter(doc.Filter, "filter")
+}
+
+func encodeFilter(f Filter) (error, *jsonFilter) {
+	jf := &jsonFilter{}
+	for _, fe := range f.GetFields() {
+		err, field := encodeField(fe)
+		if err != nil {
+			return err, nil
+		}
+		jf.Fields = append(jf.Fields, field)
+	}
+
+	groups := []struct {
+		src []Filter
+		dst *[]*jsonFilter
+	}{
+		{f.GetAnd(), &jf.And},
+		{f.GetOr(), &jf.Or},
+		{f.GetNot(), &jf.Not},
+	}
+	for _, group := range groups {
+		for _, sub := range group.src {
+			err, js := encodeFilter(sub)
+			if err != nil {
+				return err, nil
+			}
+			*group.dst = append(*group.dst, js)
+		}
+	}
+	return nil, jf
+}
+
+func encodeField(fe FieldExpression) (error, *jsonField) {
+	expr := fe.Expression()
+	if expr == nil {
+		return fmt.Errorf("field %s has no expression", fe.Name()), nil
+	}
+	code := FromOperation(expr.Op())
+	field := &jsonField{
+		Name: fe.Name(),
+		Op:   expr.Op().String(),
+		Code: &code,
+	}
+	if !expr.Op().Registered() {
+		field.Op = ""
+	}
+	if expr.Value() != nil {
+		err, value := encodeValue(reflect.ValueOf(expr.Value()))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", fe.Name(), err), nil
+		}
+		field.Value = value
+	}
+	return nil, field
+}
+
+func encodeValue(v reflect.Value) (error, *jsonValue) {
+	if re, ok := v.Interface().(*regexp.Regexp); ok {
+		v = reflect.ValueOf(re.String())
+	}
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		elem := typeName(v.Type().Elem())
+		if elem == "" {
+			elem = anyType
+		}
+		items := make([]json.RawMessage, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			var raw json.RawMessage
+			if elem == anyType {
+				var item *jsonValue
+				err, item = encodeValue(v.Index(i))
+				if err == nil {
+					raw, err = json.Marshal(item)
+				}
+			} else {
+				err, raw = encodeScalar(v.Index(i))
+			}
+			if err != nil {
+				return err, nil
+			}
+			items = append(items, raw)
+		}
+		raw, err := json.Marshal(items)
+		if err != nil {
+			return err, nil
+		}
+		return nil, &jsonValue{Type: listType, Elem: elem, Value: raw}
+	}
+
+	name := typeName(v.Type())
+	if name == "" {
+		return fmt.Errorf("value type %s isn't supported", v.Type()), nil
+	}
+	err, raw := encodeScalar(v)
+	if err != nil {
+		return err, nil
+	}
+	return nil, &jsonValue{Type: name, Value: raw}
+}
+
+func encodeScalar(v reflect.Value) (error, json.RawMessage) {
+	switch {
+	case v.Type() == timeType:
+		return marshal(v.Interface().(time.Time).Format(time.RFC3339Nano))
+	case v.Type() == durationType:
+		return marshal(v.Interface().(time.Duration).String())
+	case isInt(v):
+		return nil, json.RawMessage(strconv.FormatInt(v.Int(), 10))
+	case isUint(v):
+		return nil, json.RawMessage(strconv.FormatUint(v.Uint(), 10))
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return marshal(v.Float())
+	case v.Kind() == reflect.String:
+		return marshal(v.String())
+	case v.Kind() == reflect.Bool:
+		return marshal(v.Bool())
+	}
+	return fmt.Errorf("value type %s isn't supported", v.Type()), nil
+}
+
+func marshal(v any) (error, json.RawMessage) {
+	raw, err := json.Marshal(v)
+	return err, raw
+}
+
+// typeName returns a wire type name of the scalar type or empty string if the type isn't a supported scalar.
+func typeName(t reflect.Type) string {
+	switch {
+	case t == timeType:
+		return timeName
+	case t == durationType:
+		return durationName
+	case t.Kind() == reflect.Uintptr:
+		return ""
+	}
+	for name, st := range scalarTypes {
+		if name != timeName && name != durationName && st.Kind() == t.Kind() {
+			return name
+		}
+	}
+	return ""
+}
+
+func decodeFilter(jf *jsonFilter, path string) (error, Filter) {
+	if jf == nil {
+		return fmt.Errorf("%s: filter is null", path), nil
+	}
+	f := New()
+	for i, field := range jf.Fields {
+		err, fe := decodeField(field, fmt.Sprintf("%s.fields[%d]", path, i))
+		if err != nil {
+			return err, nil
+		}
+		f.AddField(fe)
+	}
+
+	groups := []struct {
+		name string
+		src  []*jsonFilter
+		add  func(...Filter) Filter
+	}{
+		{"and", jf.And, f.And},
+		{"or", jf.Or, f.Or},
+		{"not", jf.Not, f.Not},
+	}
+	for _, group := range groups {
+		for i, js := range group.src {
+			err, sub := decodeFilter(js, fmt.Sprintf("%s.%s[%d]", path, group.name, i))
+			if err != nil {
+				return err, nil
+			}
+			group.add(sub)
+		}
+	}
+	return nil, f
+}
+
+func decodeField(field *jsonField, path string) (error, FieldExpression) {
+	if field == nil {
+		return fmt.Errorf("%s: field is null", path), nil
+	}
+	if field.Name == "" {
+		return fmt.Errorf("%s: field name is empty", path), nil
+	}
+
+	var op Operation
+	switch {
+	case field.Op != "":
+		err, byName := ParseOperation(field.Op)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err), nil
+		}
+		if field.Code != nil && FromOperation(byName) != *field.Code {
+			return fmt.Errorf("%s: operation %q doesn't match code %d", path, field.Op, *field.Code), nil
+		}
+		op = byName
+	case field.Code != nil:
+		err, byCode := ToOperation(*field.Code)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err), nil
+		}
+		op = byCode
+	default:
+		return fmt.Errorf("%s: operation is missing", path), nil
+	}
+
+	var value any
+	if field.Value != nil {
+		err, v := decodeValue(field.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err), nil
+		}
+		value = v
+	}
+	return nil, NewFieldExpression(field.Name, NewExpression(op, value))
+}
+
+func decodeValue(jv *jsonValue) (error, any) {
+	if jv == nil {
+		return nil, nil
+	}
+	if jv.Type != listType {
+		t, ok := scalarTypes[jv.Type]
+		if !ok {
+			return fmt.Errorf("value type %q isn't supported", jv.Type), nil
+		}
+		err, v := decodeScalar(jv.Value, t)
+		if err != nil {
+			return err, nil
+		}
+		return nil, v.Interface()
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(jv.Value, &items); err != nil {
+		return err, nil
+	}
+
+	if jv.Elem == anyType {
+		list := make([]any, 0, len(items))
+		for _, raw := range items {
+			var item *jsonValue
+			if err := json.Unmarshal(raw, &item); err != nil {
+				return err, nil
+			}
+			err, v := decodeValue(item)
+			if err != nil {
+				return err, nil
+			}
+			list = append(list, v)
+		}
+		return nil, list
+	}
+
+	t, ok := scalarTypes[jv.Elem]
+	if !ok {
+		return fmt.Errorf("list element type %q isn't supported", jv.Elem), nil
+	}
+	list := reflect.MakeSlice(reflect.SliceOf(t), 0, len(items))
+	for _, raw := range items {
+		err, v := decodeScalar(raw, t)
+		if err != nil {
+			return err, nil
+		}
+		list = reflect.Append(list, v)
+	}
+	return nil, list.Interface()
+}
+
+func decodeScalar(raw json.RawMessage, t reflect.Type) (error, reflect.Value) {
+	if len(raw) == 0 {
+		return errors.New("value is missing"), reflect.Value{}
+	}
+	v := reflect.New(t).Elem()
+	switch {
+	case t == timeType || t == durationType:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err, reflect.Value{}
+		}
+		if t == timeType {
+			parsed, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return err, reflect.Value{}
+			}
+			v.Set(reflect.ValueOf(parsed))
+		} else {
+			parsed, err := time.ParseDuration(s)
+			if err != nil {
+				return err, reflect.Value{}
+			}
+			v.SetInt(int64(parsed))
+		}
+	case isInt(v):
+		n, err := strconv.ParseInt(string(raw), 10, t.Bits())
+		if err != nil {
+			return err, reflect.Value{}
+		}
+		v.SetInt(n)
+	case isUint(v):
+		n, err := strconv.ParseUint(string(raw), 10, t.Bits())
+		if err != nil {
+			return err, reflect.Value{}
+		}
+		v.SetUint(n)
+	default:
+		if err := json.Unmarshal(raw, v.Addr().Interface()); err != nil {
+			return err, reflect.Value{}
+		}
+	}
+	return nil, v
+}
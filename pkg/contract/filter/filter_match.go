@@ -0,0 +1,421 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TagName is a struct tag used to resolve filter field names into struct fields, e.g. `dao:"name"`.
+// If a struct field has no tag then its Go name is used, compared case-insensitively.
+// A field tagged with `dao:"-"` is never resolved.
+const TagName = "dao"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var errFieldNotFound = errors.New("field hasn't found")
+
+// Match evaluates the filter tree against the value. The value is a struct, a pointer to a struct or a map with
+// string keys. Nested fields are addressed by dot separated names, e.g. "address.city".
+//
+// A filter node matches when all of its fields match, all of its And filters match, at least one of its Or filters
+// matches (if there are any) and none of its Not filters matches. A nil or empty filter matches everything, so does
+// a node with an empty Or alternative. It's the semantics of the filter tree which every translation follows.
+//
+// Exists and IsNull don't fail on an unknown field or a missing map key: such field doesn't exist and is null.
+//
+// It returns error if the filter refers to an unknown field, uses an unsupported operation or the value has a shape
+// that the operation can't be applied to.
+func Match(f Filter, v any) (error, bool) {
+	if f == nil {
+		return nil, true
+	}
+	return matchFilter(f, reflect.ValueOf(v))
+}
+
+func matchFilter(f Filter, v reflect.Value) (error, bool) {
+	for _, fe := range f.GetFields() {
+		err, ok := matchField(fe, v)
+		if err != nil || !ok {
+			return err, false
+		}
+	}
+
+	for _, sub := range f.GetAnd() {
+		err, ok := matchFilter(sub, v)
+		if err != nil || !ok {
+			return err, false
+		}
+	}
+
+	if or := f.GetOr(); len(or) > 0 {
+		matched := false
+		for _, sub := range or {
+			err, ok := matchFilter(sub, v)
+			if err != nil {
+				return err, false
+			}
+			if ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, false
+		}
+	}
+
+	for _, sub := range f.GetNot() {
+		err, ok := matchFilter(sub, v)
+		if err != nil || ok {
+			return err, false
+		}
+	}
+	return nil, true
+}
+
+func matchField(fe FieldExpression, v reflect.Value) (error, bool) {
+	expr := fe.Expression()
+	if expr == nil {
+		return fmt.Errorf("field %s has no expression", fe.Name()), false
+	}
+	if !MatchSupports(expr.Op()) {
+		return fmt.Errorf("field %s: operation %s isn't supported", fe.Name(), expr.Op()), false
+	}
+	err, field := resolve(v, fe.Name())
+	if errors.Is(err, errFieldNotFound) && (expr.Op() == Exists || expr.Op() == IsNull) {
+		return nil, expr.Op() == IsNull
+	}
+	if err != nil {
+		return err, false
+	}
+	err, ok := evaluate(expr.Op(), field, expr.Value())
+	if err != nil {
+		return fmt.Errorf("field %s: %w", fe.Name(), err), false
+	}
+	return nil, ok
+}
+
+// resolve finds a value by dot separated path. A nil pointer on the path results to an invalid value.
+func resolve(v reflect.Value, path string) (error, reflect.Value) {
+	for _, name := range strings.Split(path, ".") {
+		v = indirect(v)
+		if !v.IsValid() {
+			return nil, v
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			next, ok := structField(v, name)
+			if !ok {
+				return fmt.Errorf("%w: %s in %s", errFieldNotFound, path, v.Type()), reflect.Value{}
+			}
+			v = next
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return fmt.Errorf("field %s can't be resolved in %s", path, v.Type()), reflect.Value{}
+			}
+			next := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !next.IsValid() {
+				return fmt.Errorf("%w: %s in %s", errFieldNotFound, path, v.Type()), reflect.Value{}
+			}
+			v = next
+		default:
+			return fmt.Errorf("field %s can't be resolved in %s", path, v.Type()), reflect.Value{}
+		}
+	}
+	return nil, indirect(v)
+}
+
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	fallback := -1
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(sf.Tag.Get(TagName), ",")
+		if tag == "-" {
+			continue
+		}
+		if tag == name {
+			return v.Field(i), true
+		}
+		if tag == "" && fallback < 0 && strings.EqualFold(sf.Name, name) {
+			fallback = i
+		}
+	}
+	if fallback >= 0 {
+		return v.Field(fallback), true
+	}
+	return reflect.Value{}, false
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func evaluate(op Operation, field reflect.Value, value any) (error, bool) {
+	arg := indirect(reflect.ValueOf(value))
+
+	switch op {
+	case IsNull:
+		return nil, !field.IsValid()
+	case Exists:
+		return nil, true
+	case Eq:
+		return nil, equal(field, arg)
+	case Ne:
+		return nil, !equal(field, arg)
+	case In, NotIn:
+		err, found := in(field, arg)
+		return err, err == nil && found == (op == In)
+	}
+
+	// Nil field can't satisfy any other operation.
+	if !field.IsValid() {
+		return nil, false
+	}
+
+	switch op {
+	case Contains:
+		return contains(field, arg)
+	case StartWith, EndsWith, EqIgnoreCase, ContainsIgnoreCase, StartWithIgnoreCase, EndsWithIgnoreCase:
+		err, s, sub := stringPair(field, arg)
+		if err != nil {
+			return err, false
+		}
+		switch op {
+		case StartWith:
+			return nil, strings.HasPrefix(s, sub)
+		case EndsWith:
+			return nil, strings.HasSuffix(s, sub)
+		case EqIgnoreCase:
+			return nil, strings.EqualFold(s, sub)
+		}
+		s, sub = fold(s), fold(sub)
+		switch op {
+		case ContainsIgnoreCase:
+			return nil, strings.Contains(s, sub)
+		case StartWithIgnoreCase:
+			return nil, strings.HasPrefix(s, sub)
+		}
+		return nil, strings.HasSuffix(s, sub)
+	case Gt, Gte, Lt, Lte:
+		err, c := compare(field, arg)
+		if err != nil {
+			return err, false
+		}
+		switch op {
+		case Gt:
+			return nil, c > 0
+		case Gte:
+			return nil, c >= 0
+		case Lt:
+			return nil, c < 0
+		}
+		return nil, c <= 0
+	case RegEx:
+		return regex(field, value)
+	case Between:
+		return between(field, arg)
+	}
+	return fmt.Errorf("operation %s isn't supported", op), false
+}
+
+// MatchSupports reports whether Match could evaluate the operation.
+func MatchSupports(op Operation) bool {
+	return IsBuiltin(op)
+}
+
+// equal compares the values. Nil values are only equal to each other.
+func equal(a reflect.Value, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if err, c := compare(a, b); err == nil {
+		return c == 0
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func contains(field reflect.Value, arg reflect.Value) (error, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		err, s, sub := stringPair(field, arg)
+		return err, err == nil && strings.Contains(s, sub)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < field.Len(); i++ {
+			if equal(indirect(field.Index(i)), arg) {
+				return nil, true
+			}
+		}
+		return nil, false
+	case reflect.Map:
+		if !arg.IsValid() || !arg.Type().AssignableTo(field.Type().Key()) {
+			return fmt.Errorf("value type %s doesn't match map key type %s", typeOf(arg), field.Type().Key()), false
+		}
+		if !arg.Comparable() {
+			return fmt.Errorf("value type %s can't be a map key", typeOf(arg)), false
+		}
+		return nil, field.MapIndex(arg).IsValid()
+	}
+	return fmt.Errorf("contains can't be applied to %s", field.Type()), false
+}
+
+func in(field reflect.Value, arg reflect.Value) (error, bool) {
+	if !arg.IsValid() || (arg.Kind() != reflect.Slice && arg.Kind() != reflect.Array) {
+		return fmt.Errorf("list operation expects a slice or an array, got %s", typeOf(arg)), false
+	}
+	for i := 0; i < arg.Len(); i++ {
+		if equal(field, indirect(arg.Index(i))) {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// fold maps the string into a canonical case, so case-insensitive substring checks could be done by
+// ordinary string functions.
+func fold(s string) string {
+	return strings.ToLower(strings.ToUpper(s))
+}
+
+func regex(field reflect.Value, value any) (error, bool) {
+	if field.Kind() != reflect.String {
+		return fmt.Errorf("regular expression can't be applied to %s", field.Type()), false
+	}
+	switch re := value.(type) {
+	case *regexp.Regexp:
+		return nil, re.MatchString(field.String())
+	case string:
+		compiled, err := regexp.Compile(re)
+		if err != nil {
+			return err, false
+		}
+		return nil, compiled.MatchString(field.String())
+	}
+	return fmt.Errorf("regular expression should be a string or *regexp.Regexp, got %T", value), false
+}
+
+func between(field reflect.Value, arg reflect.Value) (error, bool) {
+	if !arg.IsValid() || (arg.Kind() != reflect.Slice && arg.Kind() != reflect.Array) || arg.Len() != 2 {
+		return errors.New("between expects a range of exactly two values"), false
+	}
+	err, low := compare(field, indirect(arg.Index(0)))
+	if err != nil {
+		return err, false
+	}
+	err, high := compare(field, indirect(arg.Index(1)))
+	if err != nil {
+		return err, false
+	}
+	return nil, low >= 0 && high <= 0
+}
+
+func stringPair(field reflect.Value, arg reflect.Value) (error, string, string) {
+	if field.Kind() != reflect.String || !arg.IsValid() || arg.Kind() != reflect.String {
+		return fmt.Errorf("string operation can't be applied to %s and %s", field.Type(), typeOf(arg)), "", ""
+	}
+	return nil, field.String(), arg.String()
+}
+
+// compare returns -1, 0 or 1 if a is less, equal or greater than b. Numbers of different kinds are compared by
+// value, time.Time is compared chronologically. It returns error if the values aren't ordered or comparable
+// with each other.
+func compare(a reflect.Value, b reflect.Value) (error, int) {
+	if !b.IsValid() {
+		return fmt.Errorf("nil value can't be compared with %s", a.Type()), 0
+	}
+
+	if a.Type() == timeType && b.Type() == timeType {
+		return nil, a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	}
+
+	switch {
+	case isInt(a) && isInt(b):
+		return nil, cmp(a.Int(), b.Int())
+	case isUint(a) && isUint(b):
+		return nil, cmp(a.Uint(), b.Uint())
+	case isInt(a) && isUint(b):
+		if a.Int() < 0 {
+			return nil, -1
+		}
+		return nil, cmp(uint64(a.Int()), b.Uint())
+	case isUint(a) && isInt(b):
+		if b.Int() < 0 {
+			return nil, 1
+		}
+		return nil, cmp(a.Uint(), uint64(b.Int()))
+	case isNumber(a) && isNumber(b):
+		return nil, cmp(toFloat(a), toFloat(b))
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return nil, strings.Compare(a.String(), b.String())
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		if a.Bool() == b.Bool() {
+			return nil, 0
+		}
+		if b.Bool() {
+			return nil, -1
+		}
+		return nil, 1
+	}
+	return fmt.Errorf("%s can't be compared with %s", a.Type(), b.Type()), 0
+}
+
+func cmp[N int64 | uint64 | float64](a N, b N) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	return isInt(v) || isUint(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case isInt(v):
+		return float64(v.Int())
+	case isUint(v):
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func typeOf(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
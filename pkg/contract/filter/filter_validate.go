@@ -0,0 +1,301 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// FieldType is a type of field declared by Schema.
+type FieldType int
+
+// AnyType accepts values of every type. Only Eq, Ne, In, NotIn, IsNull and Exists are applicable by default.
+var AnyType FieldType = 0
+
+// StringType accepts string values.
+var StringType FieldType = 1
+
+// IntType accepts signed and unsigned integer values.
+var IntType FieldType = 2
+
+// FloatType accepts integer and floating-point values.
+var FloatType FieldType = 3
+
+// BoolType accepts bool values.
+var BoolType FieldType = 4
+
+// TimeType accepts time.Time values.
+var TimeType FieldType = 5
+
+// Field declares a field which filters are allowed to use.
+type Field struct {
+
+	// Type of the field. For List fields it's a type of elements.
+	Type FieldType
+
+	// List defines whether the field is a list, e.g. tags. Eq, Ne, Contains, In and NotIn take a single element
+	// of a list field.
+	List bool
+
+	// Operations allowed on the field. It's optional. If it's empty then every built-in operation applicable
+	// to the Type is allowed. Custom operations must be listed explicitly.
+	Operations []Operation
+}
+
+// Schema declares fields which filters are allowed to use by their names.
+type Schema map[string]Field
+
+// Violation is a single problem found by Validate.
+type Violation struct {
+
+	// Path of the field expression in the tree, e.g. "filter.and[0].fields[1]".
+	Path string
+
+	// Field is a name of the field.
+	Field string
+
+	// Message describes the problem.
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s (%s): %s", v.Path, v.Field, v.Message)
+}
+
+// ValidationError keeps all violations found by Validate.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.String())
+	}
+	return "invalid filter: " + strings.Join(messages, "; ")
+}
+
+var orderedTypes = map[FieldType]bool{StringType: true, IntType: true, FloatType: true, TimeType: true}
+
+// Validate checks the filter against the schema before it's passed to a DAO. It checks that every field is declared,
+// the operation is allowed and applicable to the field type and the value has the right shape: a single value of
+// the field type for unary operations, exactly two ordered bounds for Between, a list for In and NotIn, no value for
+// IsNull and Exists and a compilable pattern for RegEx. Eq and Ne accept nil value which means null.
+//
+// It returns *ValidationError with all violations or nil if the filter is valid. A nil filter is valid.
+func Validate(f Filter, schema Schema) error {
+	if f == nil {
+		return nil
+	}
+	violations := make([]Violation, 0)
+	validateFilter(f, schema, "filter", &violations)
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func validateFilter(f Filter, schema Schema, path string, violations *[]Violation) {
+	for i, fe := range f.GetFields() {
+		fieldPath := fmt.Sprintf("%s.fields[%d]", path, i)
+		if message := validateField(fe, schema); message != "" {
+			*violations = append(*violations, Violation{Path: fieldPath, Field: fe.Name(), Message: message})
+		}
+	}
+
+	groups := []struct {
+		name    string
+		filters []Filter
+	}{
+		{"and", f.GetAnd()},
+		{"or", f.GetOr()},
+		{"not", f.GetNot()},
+	}
+	for _, group := range groups {
+		for i, sub := range group.filters {
+			validateFilter(sub, schema, fmt.Sprintf("%s.%s[%d]", path, group.name, i), violations)
+		}
+	}
+}
+
+// validateField returns a description of the first problem of the field expression or empty string.
+func validateField(fe FieldExpression, schema Schema) string {
+	field, ok := schema[fe.Name()]
+	if !ok {
+		return "field isn't allowed"
+	}
+	expr := fe.Expression()
+	if expr == nil {
+		return "expression is missing"
+	}
+	op := expr.Op()
+
+	if !allowed(field, op) {
+		return fmt.Sprintf("operation %s isn't allowed", op)
+	}
+	if builtin(op) && !applicable(field, op) {
+		return fmt.Sprintf("operation %s can't be applied to %s", op, describe(field))
+	}
+
+	value := reflect.ValueOf(expr.Value())
+	switch {
+	case op == Between:
+		return validateRange(field, value)
+	case op == In || op == NotIn:
+		return validateList(field, value)
+	case op.Arity() == Nullary:
+		if value.IsValid() {
+			return fmt.Sprintf("operation %s doesn't take a value", op)
+		}
+		return ""
+	case op.Arity() == Binary:
+		return validateRange(field, value)
+	case op.Arity() == Variadic:
+		return validateList(field, value)
+	}
+
+	if !value.IsValid() {
+		if op == Eq || op == Ne {
+			return ""
+		}
+		return fmt.Sprintf("operation %s requires a value", op)
+	}
+	if op == RegEx {
+		return validatePattern(expr.Value())
+	}
+	if !accepts(field.Type, value) {
+		return fmt.Sprintf("value of type %s doesn't match field type %s", value.Type(), field.Type)
+	}
+	return ""
+}
+
+// allowed reports whether the operation is listed by the field. If the field doesn't list operations then every
+// built-in operation is allowed.
+func allowed(field Field, op Operation) bool {
+	if len(field.Operations) == 0 {
+		return builtin(op)
+	}
+	for _, allowedOp := range field.Operations {
+		if allowedOp == op {
+			return true
+		}
+	}
+	return false
+}
+
+func builtin(op Operation) bool {
+	return op != Undefined && MatchSupports(op)
+}
+
+func describe(field Field) string {
+	if field.List {
+		return "list of " + field.Type.String()
+	}
+	return field.Type.String()
+}
+
+// applicable reports whether the built-in operation makes sense for the field type.
+func applicable(field Field, op Operation) bool {
+	switch op {
+	case Eq, Ne, In, NotIn, IsNull, Exists:
+		return true
+	case Contains:
+		return field.List || field.Type == StringType
+	case StartWith, EndsWith, EqIgnoreCase, ContainsIgnoreCase, StartWithIgnoreCase, EndsWithIgnoreCase, RegEx:
+		return !field.List && field.Type == StringType
+	case Gt, Gte, Lt, Lte, Between:
+		return !field.List && orderedTypes[field.Type]
+	}
+	return false
+}
+
+func validateRange(field Field, value reflect.Value) string {
+	value = indirect(value)
+	if !value.IsValid() || (value.Kind() != reflect.Slice && value.Kind() != reflect.Array) || value.Len() != 2 {
+		return "range should have exactly two bounds"
+	}
+	low, high := indirect(value.Index(0)), indirect(value.Index(1))
+	if !low.IsValid() || !high.IsValid() {
+		return "range bounds should not be null"
+	}
+	if !accepts(field.Type, low) || !accepts(field.Type, high) {
+		return fmt.Sprintf("range bounds of types %s and %s don't match field type %s", low.Type(), high.Type(), field.Type)
+	}
+	err, c := compare(low, high)
+	if err != nil {
+		return err.Error()
+	}
+	if c > 0 {
+		return "lower bound is greater than upper bound"
+	}
+	return ""
+}
+
+func validateList(field Field, value reflect.Value) string {
+	value = indirect(value)
+	if !value.IsValid() || (value.Kind() != reflect.Slice && value.Kind() != reflect.Array) {
+		return "value should be a list"
+	}
+	for i := 0; i < value.Len(); i++ {
+		item := indirect(value.Index(i))
+		if item.IsValid() && !accepts(field.Type, item) {
+			return fmt.Sprintf("list item %d of type %s doesn't match field type %s", i, item.Type(), field.Type)
+		}
+	}
+	return ""
+}
+
+func validatePattern(value any) string {
+	switch re := value.(type) {
+	case *regexp.Regexp:
+		return ""
+	case string:
+		if _, err := regexp.Compile(re); err != nil {
+			return "invalid regular expression: " + err.Error()
+		}
+		return ""
+	}
+	return fmt.Sprintf("regular expression should be a string, got %T", value)
+}
+
+func accepts(t FieldType, v reflect.Value) bool {
+	v = indirect(v)
+	if !v.IsValid() {
+		return false
+	}
+	switch t {
+	case AnyType:
+		return true
+	case StringType:
+		return v.Kind() == reflect.String
+	case IntType:
+		return isInt(v) || isUint(v)
+	case FloatType:
+		return isNumber(v)
+	case BoolType:
+		return v.Kind() == reflect.Bool
+	case TimeType:
+		return v.Type() == timeType
+	}
+	return false
+}
+
+// String returns a name of the field type.
+func (t FieldType) String() string {
+	switch t {
+	case AnyType:
+		return "any"
+	case StringType:
+		return "string"
+	case IntType:
+		return "int"
+	case FloatType:
+		return "float"
+	case BoolType:
+		return "bool"
+	case TimeType:
+		return "time"
+	}
+	return fmt.Sprintf("type(%d)", int(t))
+}
@@ -0,0 +1,200 @@
+package mongofilter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"reflect"
+	"regexp"
+)
+
+// Document is a MongoDB-style query document. It's a plain map, so it could be passed into any driver that
+// accepts maps, e.g. as bson.M.
+type Document = map[string]any
+
+// Translate converts the filter tree into a query document. A nil or empty filter produces an empty document
+// that matches everything.
+//
+// The tree follows the semantics of filter.Match, the parts of a node are joined by $and. A single condition isn't
+// wrapped into $and.
+//
+// Operations are mapped as follows:
+//
+// - Eq and Ne are $eq and $ne. For array fields they check whether the array contains the value.
+//
+// - In and NotIn are $in and $nin.
+//
+// - IsNull is {$eq: null}, it matches missing fields too. Exists is {$exists: true}.
+//
+// - Contains, StartWith and EndsWith are $regex with the value escaped by regexp.QuoteMeta. Case-insensitive
+// variants add {$options: "i"}, EqIgnoreCase is an anchored $regex.
+//
+// - Gt, Gte, Lt, Lte are $gt, $gte, $lt, $lte.
+//
+// - RegEx is $regex with the value as is.
+//
+// - Between is {$gte: low, $lte: high}.
+func Translate(f filter.Filter) (error, Document) {
+	if f == nil {
+		return nil, Document{}
+	}
+	return translate(f)
+}
+
+func translate(f filter.Filter) (error, Document) {
+	parts := make([]any, 0)
+
+	for _, fe := range f.GetFields() {
+		err, doc := field(fe)
+		if err != nil {
+			return err, nil
+		}
+		parts = append(parts, doc)
+	}
+
+	for _, sub := range f.GetAnd() {
+		err, doc := translate(sub)
+		if err != nil {
+			return err, nil
+		}
+		if len(doc) > 0 {
+			parts = append(parts, doc)
+		}
+	}
+
+	if or := f.GetOr(); len(or) > 0 {
+		alternatives := make([]any, 0, len(or))
+		for _, sub := range or {
+			err, doc := translate(sub)
+			if err != nil {
+				return err, nil
+			}
+			if len(doc) == 0 {
+				alternatives = nil
+				break
+			}
+			alternatives = append(alternatives, doc)
+		}
+		if alternatives != nil {
+			parts = append(parts, Document{"$or": alternatives})
+		}
+	}
+
+	for _, sub := range f.GetNot() {
+		err, doc := translate(sub)
+		if err != nil {
+			return err, nil
+		}
+		parts = append(parts, Document{"$nor": []any{doc}})
+	}
+
+	switch len(parts) {
+	case 0:
+		return nil, Document{}
+	case 1:
+		return nil, parts[0].(Document)
+	}
+	return nil, Document{"$and": parts}
+}
+
+func field(fe filter.FieldExpression) (error, Document) {
+	expr := fe.Expression()
+	if expr == nil {
+		return fmt.Errorf("field %s has no expression", fe.Name()), nil
+	}
+	err, condition := operator(expr.Op(), expr.Value())
+	if err != nil {
+		return fmt.Errorf("field %s: %w", fe.Name(), err), nil
+	}
+	return nil, Document{fe.Name(): condition}
+}
+
+func operator(op filter.Operation, value any) (error, Document) {
+	switch op {
+	case filter.Eq:
+		return nil, Document{"$eq": value}
+	case filter.Ne:
+		return nil, Document{"$ne": value}
+	case filter.IsNull:
+		return nil, Document{"$eq": nil}
+	case filter.Exists:
+		return nil, Document{"$exists": true}
+	case filter.In, filter.NotIn:
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+			return fmt.Errorf("list operation expects a slice or an array, got %T", value), nil
+		}
+		list := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, v.Index(i).Interface())
+		}
+		if op == filter.In {
+			return nil, Document{"$in": list}
+		}
+		return nil, Document{"$nin": list}
+	case filter.Contains, filter.StartWith, filter.EndsWith,
+		filter.EqIgnoreCase, filter.ContainsIgnoreCase, filter.StartWithIgnoreCase, filter.EndsWithIgnoreCase:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("pattern operation expects a string, got %T", value), nil
+		}
+		pattern := regexp.QuoteMeta(s)
+		switch op {
+		case filter.StartWith, filter.StartWithIgnoreCase:
+			pattern = "^" + pattern
+		case filter.EndsWith, filter.EndsWithIgnoreCase:
+			pattern = pattern + "$"
+		case filter.EqIgnoreCase:
+			pattern = "^" + pattern + "$"
+		}
+		switch op {
+		case filter.EqIgnoreCase, filter.ContainsIgnoreCase, filter.StartWithIgnoreCase, filter.EndsWithIgnoreCase:
+			return nil, Document{"$regex": pattern, "$options": "i"}
+		}
+		return nil, Document{"$regex": pattern}
+	case filter.Gt:
+		return nil, Document{"$gt": value}
+	case filter.Gte:
+		return nil, Document{"$gte": value}
+	case filter.Lt:
+		return nil, Document{"$lt": value}
+	case filter.Lte:
+		return nil, Document{"$lte": value}
+	case filter.RegEx:
+		switch re := value.(type) {
+		case string:
+			return nil, Document{"$regex": re}
+		case *regexp.Regexp:
+			return nil, Document{"$regex": re.String()}
+		}
+		return fmt.Errorf("regular expression should be a string or *regexp.Regexp, got %T", value), nil
+	case filter.Between:
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() != 2 {
+			return errors.New("between expects a range of exactly two values"), nil
+		}
+		return nil, Document{"$gte": v.Index(0).Interface(), "$lte": v.Index(1).Interface()}
+	}
+	return fmt.Errorf("operation %s isn't supported", op), nil
+}
+
+// Supports reports whether Translate could translate the operation.
+func Supports(op filter.Operation) bool {
+	return filter.IsBuiltin(op)
+}
+
+// Translator is a filter.Supporter of Translate, so a filter could be checked by filter.CheckSupported before it's
+// translated.
+type Translator struct{}
+
+var _ filter.Supporter = Translator{}
+
+// Translate calls Translate function.
+func (Translator) Translate(f filter.Filter) (error, Document) {
+	return Translate(f)
+}
+
+// Supports calls Supports function.
+func (Translator) Supports(op filter.Operation) bool {
+	return Supports(op)
+}
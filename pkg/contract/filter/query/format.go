@@ -0,0 +1,195 @@
+package query
+
+import (
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var symbols = map[string]filter.Operation{
+	"=":  filter.Eq,
+	"~":  filter.Contains,
+	"^=": filter.StartWith,
+	">":  filter.Gt,
+	">=": filter.Gte,
+	"<":  filter.Lt,
+	"<=": filter.Lte,
+	"=~": filter.RegEx,
+	"!=": filter.Ne,
+	"$=": filter.EndsWith,
+}
+
+var identifier = regexp.MustCompile(`^[\pL_][\pL\pN_.]*$`)
+
+// Format prints the filter in the query language, so Parse of the result produces an equivalent filter.
+// Time values are printed as RFC 3339 strings, durations and other values with unknown shape are printed as
+// strings by fmt. Operations that have no operator are printed by their names. The printed expression follows
+// the semantics of filter.Match.
+func Format(f filter.Filter) string {
+	if f == nil {
+		return ""
+	}
+	return format(f).text
+}
+
+type expression struct {
+	text     string
+	compound bool
+}
+
+// nested returns the text in parentheses if the expression is compound. Compound operands are always wrapped,
+// so the nesting of the tree is kept by the text.
+func (e expression) nested() string {
+	if e.compound {
+		return "(" + e.text + ")"
+	}
+	return e.text
+}
+
+func join(parts []expression, sep string) expression {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		texts = append(texts, part.nested())
+	}
+	return expression{text: strings.Join(texts, sep), compound: true}
+}
+
+func format(f filter.Filter) expression {
+	parts := make([]expression, 0)
+
+	for _, fe := range f.GetFields() {
+		parts = append(parts, expression{text: formatField(fe)})
+	}
+
+	for _, sub := range f.GetAnd() {
+		if e := format(sub); e.text != "" {
+			parts = append(parts, e)
+		}
+	}
+
+	if or := f.GetOr(); len(or) > 0 {
+		alternatives := make([]expression, 0, len(or))
+		for _, sub := range or {
+			e := format(sub)
+			if e.text == "" {
+				alternatives = nil
+				break
+			}
+			alternatives = append(alternatives, e)
+		}
+		if len(alternatives) > 0 {
+			parts = append(parts, join(alternatives, " OR "))
+		}
+	}
+
+	for _, sub := range f.GetNot() {
+		e := format(sub)
+		if e.text == "" {
+			e.text = "()"
+		}
+		parts = append(parts, expression{text: "NOT " + e.nested()})
+	}
+
+	if len(parts) == 0 {
+		return expression{}
+	}
+	return join(parts, " AND ")
+}
+
+func formatField(fe filter.FieldExpression) string {
+	name := fe.Name()
+	if !identifier.MatchString(name) || isKeyword(name) {
+		name = "`" + name + "`"
+	}
+
+	expr := fe.Expression()
+	if expr == nil {
+		return name + " = NULL"
+	}
+
+	switch {
+	case expr.Op() == filter.IsNull && expr.Value() == nil:
+		return name + " IS NULL"
+	case expr.Op() == filter.In:
+		return name + " IN " + formatValue(reflect.ValueOf(expr.Value()))
+	case expr.Op() == filter.NotIn:
+		return name + " NOT IN " + formatValue(reflect.ValueOf(expr.Value()))
+	}
+
+	if expr.Op() == filter.Between {
+		v := reflect.ValueOf(expr.Value())
+		if v.IsValid() && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() == 2 {
+			return name + " BETWEEN " + formatValue(v.Index(0)) + " AND " + formatValue(v.Index(1))
+		}
+	}
+
+	for symbol, op := range symbols {
+		if op == expr.Op() {
+			return name + " " + symbol + " " + formatValue(reflect.ValueOf(expr.Value()))
+		}
+	}
+	if expr.Op().Arity() == filter.Nullary && expr.Value() == nil {
+		return name + " " + expr.Op().String()
+	}
+	return name + " " + expr.Op().String() + " " + formatValue(reflect.ValueOf(expr.Value()))
+}
+
+func formatValue(v reflect.Value) string {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return "NULL"
+		}
+		if re, ok := v.Interface().(*regexp.Regexp); ok {
+			return strconv.Quote(re.String())
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "NULL"
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return strconv.Quote(value.Format(time.RFC3339Nano))
+	case time.Duration:
+		return strconv.Quote(value.String())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			return "TRUE"
+		}
+		return "FALSE"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		s := strconv.FormatFloat(v.Float(), 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, formatValue(v.Index(i)))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return strconv.Quote(fmt.Sprint(v.Interface()))
+}
+
+func isKeyword(name string) bool {
+	return keywords[strings.ToUpper(name)]
+}
@@ -0,0 +1,204 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+var keywords = map[string]bool{
+	"AND":     true,
+	"OR":      true,
+	"NOT":     true,
+	"BETWEEN": true,
+	"IN":      true,
+	"IS":      true,
+	"TRUE":    true,
+	"FALSE":   true,
+	"NULL":    true,
+}
+
+var punctuation = map[rune]tokenKind{
+	'(': tokenLParen,
+	')': tokenRParen,
+	'[': tokenLBracket,
+	']': tokenRBracket,
+	',': tokenComma,
+}
+
+// operators are ordered so that longer operators are checked before their prefixes.
+var operators = []string{">=", "<=", "^=", "$=", "!=", "=~", "=", "~", ">", "<"}
+
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int
+	column int
+}
+
+// SyntaxError describes a problem in the query text. Line and Column start from 1, Column counts runes.
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+type lexer struct {
+	src    string
+	pos    int
+	line   int
+	column int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1, column: 1}
+}
+
+func (l *lexer) errorf(line int, column int, format string, args ...any) error {
+	return &SyntaxError{Line: line, Column: column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) peekRune() rune {
+	if l.pos >= len(l.src) {
+		return utf8.RuneError
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return r
+}
+
+func (l *lexer) advance() rune {
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	l.pos += size
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+func (l *lexer) next() (error, token) {
+	for l.pos < len(l.src) && unicode.IsSpace(l.peekRune()) {
+		l.advance()
+	}
+	if l.pos >= len(l.src) {
+		return nil, token{kind: tokenEOF, line: l.line, column: l.column}
+	}
+
+	line, column, start := l.line, l.column, l.pos
+	r := l.peekRune()
+
+	switch {
+	case punctuation[r] != tokenEOF:
+		l.advance()
+		return nil, token{kind: punctuation[r], text: string(r), line: line, column: column}
+	case r == '"':
+		return l.quoted(line, column)
+	case r == '`':
+		l.advance()
+		for l.pos < len(l.src) && l.peekRune() != '`' {
+			l.advance()
+		}
+		if l.pos >= len(l.src) {
+			return l.errorf(line, column, "unterminated identifier"), token{}
+		}
+		l.advance()
+		name := l.src[start+1 : l.pos-1]
+		if name == "" {
+			return l.errorf(line, column, "empty identifier"), token{}
+		}
+		return nil, token{kind: tokenIdent, text: name, line: line, column: column}
+	case r == '-' || r == '+' || unicode.IsDigit(r):
+		l.advance()
+		for l.pos < len(l.src) && strings.ContainsRune("0123456789.eE+-", l.peekRune()) {
+			prev := l.src[l.pos-1]
+			if (l.peekRune() == '+' || l.peekRune() == '-') && prev != 'e' && prev != 'E' {
+				break
+			}
+			l.advance()
+		}
+		return nil, token{kind: tokenNumber, text: l.src[start:l.pos], line: line, column: column}
+	case r == '_' || unicode.IsLetter(r):
+		for l.pos < len(l.src) && isIdentRune(l.peekRune()) {
+			l.advance()
+		}
+		text := l.src[start:l.pos]
+		if keywords[strings.ToUpper(text)] {
+			return nil, token{kind: tokenKeyword, text: strings.ToUpper(text), line: line, column: column}
+		}
+		return nil, token{kind: tokenIdent, text: text, line: line, column: column}
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			for range op {
+				l.advance()
+			}
+			return nil, token{kind: tokenOperator, text: op, line: line, column: column}
+		}
+	}
+	return l.errorf(line, column, "unexpected character %q", r), token{}
+}
+
+func (l *lexer) quoted(line int, column int) (error, token) {
+	var b strings.Builder
+	l.advance()
+	for {
+		if l.pos >= len(l.src) {
+			return l.errorf(line, column, "unterminated string"), token{}
+		}
+		r := l.advance()
+		switch r {
+		case '"':
+			return nil, token{kind: tokenString, text: b.String(), line: line, column: column}
+		case '\n':
+			return l.errorf(line, column, "unterminated string"), token{}
+		case '\\':
+			if l.pos >= len(l.src) {
+				return l.errorf(line, column, "unterminated string"), token{}
+			}
+			escLine, escColumn := l.line, l.column-1
+			e := l.advance()
+			switch e {
+			case '"', '\\':
+				b.WriteRune(e)
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case 'r':
+				b.WriteRune('\r')
+			default:
+				return l.errorf(escLine, escColumn, "unknown escape sequence \\%c", e), token{}
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
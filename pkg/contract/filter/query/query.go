@@ -0,0 +1,357 @@
+package query
+
+import (
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"strconv"
+	"strings"
+)
+
+// Parse converts the query text into a filter tree built by filter.New. The grammar is:
+//
+//	query      = [ or ]
+//	or         = and { "OR" and }
+//	and        = not { "AND" not }
+//	not        = "NOT" not | "(" [ or ] ")" | comparison
+//	comparison = field operator value | field "BETWEEN" value "AND" value | field [ "NOT" ] "IN" list |
+//	             field "IS" "NULL" | field operation [ value ]
+//	field      = identifier | "`" any text "`"
+//	value      = string | number | "TRUE" | "FALSE" | "NULL" | list
+//	list       = "[" [ value { "," value } ] "]"
+//
+// Keywords are case-insensitive. Identifiers consist of letters, digits, underscores and dots. Strings are
+// double-quoted and support \", \\, \n, \t and \r escapes. Integer numbers are int, other numbers are float64.
+// Empty parentheses are an empty filter which matches everything.
+//
+// Operators are mapped into filter operations as follows:
+//
+//	=   Eq          >   Gt          <   Lt          =~  RegEx
+//	!=  Ne          >=  Gte         <=  Lte         ^=  StartWith
+//	~   Contains                                    $=  EndsWith
+//
+// BETWEEN produces Between operation with []any{low, high} value. IN, NOT IN and IS NULL produce In, NotIn and
+// IsNull operations. Any other operation, built-in or registered by filter.RegisterOperation, could be used
+// by its name instead of an operator, e.g. `name eqIgnoreCase "john"` or `location geoWithin [1, 2]`.
+// Nullary operations are written without a value, e.g. `deletedAt exists`.
+//
+// AND binds tighter than OR, NOT binds tighter than AND. It returns *SyntaxError if the text is malformed.
+func Parse(text string) (error, filter.Filter) {
+	p := &parser{lexer: newLexer(text)}
+	if err := p.advance(); err != nil {
+		return err, nil
+	}
+	if p.tok.kind == tokenEOF {
+		return nil, filter.New()
+	}
+
+	err, n := p.or()
+	if err != nil {
+		return err, nil
+	}
+	if p.tok.kind != tokenEOF {
+		return p.unexpected(), nil
+	}
+	return nil, n.build()
+}
+
+//===========================================================================
+
+type nodeKind int
+
+const (
+	nodeEmpty nodeKind = iota
+	nodeField
+	nodeAnd
+	nodeOr
+	nodeNot
+)
+
+type node struct {
+	kind     nodeKind
+	field    filter.FieldExpression
+	children []*node
+}
+
+// build converts the syntax tree into a filter. Comparisons of a conjunction become fields of one filter,
+// negations become its Not filters and the other operands become its And filters.
+func (n *node) build() filter.Filter {
+	f := filter.New()
+	switch n.kind {
+	case nodeField:
+		f.AddField(n.field)
+	case nodeOr:
+		for _, child := range n.children {
+			f.Or(child.build())
+		}
+	case nodeNot:
+		f.Not(n.children[0].build())
+	case nodeAnd:
+		for _, child := range n.children {
+			switch child.kind {
+			case nodeField:
+				f.AddField(child.field)
+			case nodeNot:
+				f.Not(child.children[0].build())
+			case nodeEmpty:
+			default:
+				f.And(child.build())
+			}
+		}
+	}
+	return f
+}
+
+//===========================================================================
+
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+func (p *parser) advance() error {
+	err, tok := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return p.lexer.errorf(p.tok.line, p.tok.column, format, args...)
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return p.errorf("unexpected end of query")
+	}
+	return p.errorf("unexpected %q", p.tok.text)
+}
+
+func (p *parser) keyword(word string) bool {
+	return p.tok.kind == tokenKeyword && p.tok.text == word
+}
+
+func (p *parser) or() (error, *node) {
+	err, first := p.and()
+	if err != nil {
+		return err, nil
+	}
+	children := []*node{first}
+	for p.keyword("OR") {
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		err, next := p.and()
+		if err != nil {
+			return err, nil
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return nil, first
+	}
+	return nil, &node{kind: nodeOr, children: children}
+}
+
+func (p *parser) and() (error, *node) {
+	err, first := p.not()
+	if err != nil {
+		return err, nil
+	}
+	children := []*node{first}
+	for p.keyword("AND") {
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		err, next := p.not()
+		if err != nil {
+			return err, nil
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return nil, first
+	}
+	return nil, &node{kind: nodeAnd, children: children}
+}
+
+func (p *parser) not() (error, *node) {
+	switch {
+	case p.keyword("NOT"):
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		err, operand := p.not()
+		if err != nil {
+			return err, nil
+		}
+		return nil, &node{kind: nodeNot, children: []*node{operand}}
+	case p.tok.kind == tokenLParen:
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		if p.tok.kind == tokenRParen {
+			return p.advance(), &node{kind: nodeEmpty}
+		}
+		err, inner := p.or()
+		if err != nil {
+			return err, nil
+		}
+		if p.tok.kind != tokenRParen {
+			return p.errorf("expected \")\""), nil
+		}
+		return p.advance(), inner
+	case p.tok.kind == tokenIdent:
+		return p.comparison()
+	}
+	return p.unexpected(), nil
+}
+
+func (p *parser) comparison() (error, *node) {
+	name := p.tok.text
+	if err := p.advance(); err != nil {
+		return err, nil
+	}
+
+	if p.keyword("BETWEEN") {
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		err, low := p.value()
+		if err != nil {
+			return err, nil
+		}
+		if !p.keyword("AND") {
+			return p.errorf("expected AND in BETWEEN expression"), nil
+		}
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		err, high := p.value()
+		if err != nil {
+			return err, nil
+		}
+		return nil, leaf(name, filter.Between, []any{low, high})
+	}
+
+	switch {
+	case p.keyword("IN"):
+		return p.list(name, filter.In)
+	case p.keyword("NOT"):
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		if !p.keyword("IN") {
+			return p.errorf("expected IN after NOT"), nil
+		}
+		return p.list(name, filter.NotIn)
+	case p.keyword("IS"):
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		if !p.keyword("NULL") {
+			return p.errorf("expected NULL after IS"), nil
+		}
+		return p.advance(), leaf(name, filter.IsNull, nil)
+	}
+
+	var op filter.Operation
+	switch p.tok.kind {
+	case tokenOperator:
+		op = symbols[p.tok.text]
+	case tokenIdent:
+		err, named := filter.ParseOperation(p.tok.text)
+		if err != nil {
+			return p.errorf("unknown operation %s", p.tok.text), nil
+		}
+		op = named
+	default:
+		return p.errorf("expected operator after field %s", name), nil
+	}
+	if err := p.advance(); err != nil {
+		return err, nil
+	}
+	if op.Arity() == filter.Nullary {
+		return nil, leaf(name, op, nil)
+	}
+	err, value := p.value()
+	if err != nil {
+		return err, nil
+	}
+	return nil, leaf(name, op, value)
+}
+
+func (p *parser) list(name string, op filter.Operation) (error, *node) {
+	if err := p.advance(); err != nil {
+		return err, nil
+	}
+	if p.tok.kind != tokenLBracket {
+		return p.errorf("expected list after %s", op), nil
+	}
+	err, value := p.value()
+	if err != nil {
+		return err, nil
+	}
+	return nil, leaf(name, op, value)
+}
+
+func (p *parser) value() (error, any) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenString:
+		return p.advance(), tok.text
+	case tokenNumber:
+		if n, err := strconv.Atoi(tok.text); err == nil {
+			return p.advance(), n
+		}
+		if !strings.ContainsAny(tok.text, ".eE") {
+			return p.errorf("integer %s is out of range", tok.text), nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return p.errorf("invalid number %s", tok.text), nil
+		}
+		return p.advance(), f
+	case tokenKeyword:
+		switch tok.text {
+		case "TRUE":
+			return p.advance(), true
+		case "FALSE":
+			return p.advance(), false
+		case "NULL":
+			return p.advance(), nil
+		}
+	case tokenLBracket:
+		if err := p.advance(); err != nil {
+			return err, nil
+		}
+		list := make([]any, 0)
+		if p.tok.kind == tokenRBracket {
+			return p.advance(), list
+		}
+		for {
+			err, item := p.value()
+			if err != nil {
+				return err, nil
+			}
+			list = append(list, item)
+			if p.tok.kind == tokenRBracket {
+				return p.advance(), list
+			}
+			if p.tok.kind != tokenComma {
+				return p.errorf("expected \",\" or \"]\""), nil
+			}
+			if err := p.advance(); err != nil {
+				return err, nil
+			}
+		}
+	}
+	if tok.kind == tokenEOF {
+		return p.errorf("expected value, got end of query"), nil
+	}
+	return p.errorf("expected value, got %q", tok.text), nil
+}
+
+func leaf(name string, op filter.Operation, value any) *node {
+	return &node{kind: nodeField, field: filter.NewFieldExpression(name, filter.NewExpression(op, value))}
+}
@@ -0,0 +1,110 @@
+package sqlfilter
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes SQL syntax differences between databases that matter for WHERE clauses.
+type Dialect interface {
+
+	// Placeholder returns a bind parameter for the n-th argument. The n starts from 1.
+	Placeholder(n int) string
+
+	// Arg wraps the value of the n-th argument into the form expected by the driver.
+	Arg(n int, value any) any
+
+	// Quote quotes an identifier. Dot separated identifiers are quoted part by part.
+	Quote(identifier string) string
+
+	// RegEx returns a predicate checking the column against a regular expression bound by the placeholder.
+	RegEx(column string, placeholder string) string
+}
+
+var (
+	// MySQL uses `?` placeholders, back-tick quoting and REGEXP operator. It also fits MariaDB and SQLite
+	// with a registered REGEXP function.
+	MySQL Dialect = &mysqlDialect{}
+
+	// PostgreSQL uses `$1` placeholders, double-quote quoting and `~` operator.
+	PostgreSQL Dialect = &postgresDialect{}
+
+	// Oracle uses `:p1` named placeholders, double-quote quoting and REGEXP_LIKE function. Arguments are
+	// wrapped into sql.NamedArg.
+	Oracle Dialect = &oracleDialect{}
+)
+
+//===========================================================================
+
+type mysqlDialect struct{}
+
+func (d *mysqlDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (d *mysqlDialect) Arg(n int, value any) any {
+	return value
+}
+
+func (d *mysqlDialect) Quote(identifier string) string {
+	return quote(identifier, "`")
+}
+
+func (d *mysqlDialect) RegEx(column string, placeholder string) string {
+	return column + " REGEXP " + placeholder
+}
+
+//===========================================================================
+
+type postgresDialect struct{}
+
+func (d *postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (d *postgresDialect) Arg(n int, value any) any {
+	return value
+}
+
+func (d *postgresDialect) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+func (d *postgresDialect) RegEx(column string, placeholder string) string {
+	return column + " ~ " + placeholder
+}
+
+//===========================================================================
+
+type oracleDialect struct{}
+
+func (d *oracleDialect) Placeholder(n int) string {
+	return ":" + argName(n)
+}
+
+func (d *oracleDialect) Arg(n int, value any) any {
+	return sql.Named(argName(n), value)
+}
+
+func (d *oracleDialect) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+func (d *oracleDialect) RegEx(column string, placeholder string) string {
+	return "REGEXP_LIKE(" + column + ", " + placeholder + ")"
+}
+
+func argName(n int) string {
+	return "p" + strconv.Itoa(n)
+}
+
+//===========================================================================
+
+func quote(identifier string, q string) string {
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		parts[i] = q + strings.ReplaceAll(part, q, q+q) + q
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,292 @@
+package sqlfilter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"reflect"
+	"strings"
+)
+
+// likeEscape is an escape character of LIKE patterns. It isn't a backslash because backslash has a special meaning
+// in MySQL string literals.
+const likeEscape = "!"
+
+var likeReplacer = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// Compiler translates filter.Filter trees into parameterized WHERE clauses. Filter field names are never put
+// into SQL as is, they must be mapped to columns by the allowlist. The Compiler is immutable and safe for
+// concurrent use.
+type Compiler struct {
+	dialect Dialect
+	columns map[string]string
+}
+
+var _ filter.Supporter = (*Compiler)(nil)
+
+// New creates a Compiler. The columns map is an allowlist of filter field names to column names. Column names
+// are quoted by the dialect, a column could be qualified by a table, e.g. "u.name".
+func New(dialect Dialect, columns map[string]string) *Compiler {
+	allowed := make(map[string]string, len(columns))
+	for field, column := range columns {
+		allowed[field] = column
+	}
+	return &Compiler{
+		dialect: dialect,
+		columns: allowed,
+	}
+}
+
+// Compile returns a WHERE fragment without WHERE keyword and its arguments. If the filter is nil or empty then
+// the fragment is empty and the clause should be omitted.
+//
+// The tree follows the semantics of filter.Match, the parts of a node are joined by AND.
+func (c *Compiler) Compile(f filter.Filter) (error, string, []any) {
+	return c.CompileFrom(f, 0)
+}
+
+// CompileFrom is Compile for queries which already have bound arguments. The bound is a number of arguments that
+// precede the fragment, so placeholders are numbered from bound + 1.
+func (c *Compiler) CompileFrom(f filter.Filter, bound int) (error, string, []any) {
+	if f == nil {
+		return nil, "", []any{}
+	}
+	b := &builder{compiler: c, bound: bound, args: make([]any, 0)}
+	err, where := b.filter(f)
+	if err != nil {
+		return err, "", nil
+	}
+	if where.sql == "" {
+		return nil, "", []any{}
+	}
+	return nil, where.sql, b.args
+}
+
+type builder struct {
+	compiler *Compiler
+	bound    int
+	args     []any
+}
+
+func (b *builder) bind(value any) string {
+	n := b.bound + len(b.args) + 1
+	b.args = append(b.args, b.compiler.dialect.Arg(n, value))
+	return b.compiler.dialect.Placeholder(n)
+}
+
+// predicate is a part of WHERE clause. Compound predicates are joined by AND/OR and need parentheses when they
+// are nested into other predicates.
+type predicate struct {
+	sql      string
+	compound bool
+}
+
+func (p predicate) nested() string {
+	if p.compound {
+		return "(" + p.sql + ")"
+	}
+	return p.sql
+}
+
+// filter returns an empty predicate for an empty node.
+func (b *builder) filter(f filter.Filter) (error, predicate) {
+	parts := make([]predicate, 0)
+
+	for _, fe := range f.GetFields() {
+		err, part := b.field(fe)
+		if err != nil {
+			return err, predicate{}
+		}
+		parts = append(parts, predicate{sql: part})
+	}
+
+	for _, sub := range f.GetAnd() {
+		err, part := b.filter(sub)
+		if err != nil {
+			return err, predicate{}
+		}
+		if part.sql != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if or := f.GetOr(); len(or) > 0 {
+		alternatives := make([]predicate, 0, len(or))
+		for _, sub := range or {
+			err, part := b.filter(sub)
+			if err != nil {
+				return err, predicate{}
+			}
+			if part.sql == "" {
+				alternatives = nil
+				break
+			}
+			alternatives = append(alternatives, part)
+		}
+		if alternatives != nil {
+			parts = append(parts, join(alternatives, " OR "))
+		}
+	}
+
+	for _, sub := range f.GetNot() {
+		err, part := b.filter(sub)
+		if err != nil {
+			return err, predicate{}
+		}
+		if part.sql == "" {
+			part.sql = "1 = 1"
+		}
+		parts = append(parts, predicate{sql: "NOT " + part.nested()})
+	}
+
+	return nil, join(parts, " AND ")
+}
+
+func (b *builder) field(fe filter.FieldExpression) (error, string) {
+	column, ok := b.compiler.columns[fe.Name()]
+	if !ok {
+		return fmt.Errorf("field %s isn't allowed", fe.Name()), ""
+	}
+	column = b.compiler.dialect.Quote(column)
+
+	expr := fe.Expression()
+	if expr == nil {
+		return fmt.Errorf("field %s has no expression", fe.Name()), ""
+	}
+	value := expr.Value()
+
+	switch expr.Op() {
+	case filter.Eq:
+		if isNil(value) {
+			return nil, column + " IS NULL"
+		}
+		return nil, column + " = " + b.bind(value)
+	case filter.Ne:
+		if isNil(value) {
+			return nil, column + " IS NOT NULL"
+		}
+		return nil, column + " <> " + b.bind(value)
+	case filter.IsNull:
+		return nil, column + " IS NULL"
+	case filter.Exists:
+		return nil, column + " IS NOT NULL"
+	case filter.In, filter.NotIn:
+		return b.list(fe, column, expr.Op(), value)
+	case filter.EqIgnoreCase:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("field %s: case-insensitive operation expects a string, got %T", fe.Name(), value), ""
+		}
+		return nil, "LOWER(" + column + ") = LOWER(" + b.bind(s) + ")"
+	case filter.Contains, filter.StartWith, filter.EndsWith,
+		filter.ContainsIgnoreCase, filter.StartWithIgnoreCase, filter.EndsWithIgnoreCase:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("field %s: like operation expects a string, got %T", fe.Name(), value), ""
+		}
+		pattern := likeReplacer.Replace(s)
+		switch expr.Op() {
+		case filter.Contains, filter.ContainsIgnoreCase:
+			pattern = "%" + pattern + "%"
+		case filter.StartWith, filter.StartWithIgnoreCase:
+			pattern = pattern + "%"
+		default:
+			pattern = "%" + pattern
+		}
+		switch expr.Op() {
+		case filter.ContainsIgnoreCase, filter.StartWithIgnoreCase, filter.EndsWithIgnoreCase:
+			return nil, "LOWER(" + column + ") LIKE LOWER(" + b.bind(pattern) + ") ESCAPE '" + likeEscape + "'"
+		}
+		return nil, column + " LIKE " + b.bind(pattern) + " ESCAPE '" + likeEscape + "'"
+	case filter.Gt:
+		return b.comparison(fe, column, " > ", value)
+	case filter.Gte:
+		return b.comparison(fe, column, " >= ", value)
+	case filter.Lt:
+		return b.comparison(fe, column, " < ", value)
+	case filter.Lte:
+		return b.comparison(fe, column, " <= ", value)
+	case filter.RegEx:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("field %s: regular expression should be a string, got %T", fe.Name(), value), ""
+		}
+		return nil, b.compiler.dialect.RegEx(column, b.bind(s))
+	case filter.Between:
+		err, low, high := bounds(value)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", fe.Name(), err), ""
+		}
+		return nil, column + " BETWEEN " + b.bind(low) + " AND " + b.bind(high)
+	}
+	return fmt.Errorf("field %s: operation %s isn't supported", fe.Name(), expr.Op()), ""
+}
+
+// Supports reports whether the Compiler could translate the operation.
+func (c *Compiler) Supports(op filter.Operation) bool {
+	return filter.IsBuiltin(op)
+}
+
+// list translates In and NotIn. An empty list can't contain anything, so In is always false and NotIn is always true.
+func (b *builder) list(fe filter.FieldExpression, column string, op filter.Operation, value any) (error, string) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return fmt.Errorf("field %s: list operation expects a slice or an array, got %T", fe.Name(), value), ""
+	}
+	if v.Len() == 0 {
+		if op == filter.In {
+			return nil, "1 = 0"
+		}
+		return nil, "1 = 1"
+	}
+	placeholders := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		placeholders = append(placeholders, b.bind(v.Index(i).Interface()))
+	}
+	keyword := " IN ("
+	if op == filter.NotIn {
+		keyword = " NOT IN ("
+	}
+	return nil, column + keyword + strings.Join(placeholders, ", ") + ")"
+}
+
+func (b *builder) comparison(fe filter.FieldExpression, column string, op string, value any) (error, string) {
+	if isNil(value) {
+		return fmt.Errorf("field %s: nil value can't be compared", fe.Name()), ""
+	}
+	return nil, column + op + b.bind(value)
+}
+
+func bounds(value any) (error, any, any) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() != 2 {
+		return errors.New("between expects a range of exactly two values"), nil, nil
+	}
+	return nil, v.Index(0).Interface(), v.Index(1).Interface()
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func join(parts []predicate, sep string) predicate {
+	switch len(parts) {
+	case 0:
+		return predicate{}
+	case 1:
+		return parts[0]
+	}
+	nested := make([]string, 0, len(parts))
+	for _, part := range parts {
+		nested = append(nested, part.nested())
+	}
+	return predicate{sql: strings.Join(nested, sep), compound: true}
+}
@@ -0,0 +1,212 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/factory"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrShutdown is returned by tracked factories after Shutdown has been started.
+var ErrShutdown = errors.New("lifecycle is shutting down")
+
+// Manager records closers, usually DAOs made by tracked factories, and closes them on Shutdown.
+type Manager struct {
+	entries  []*entry
+	shutdown bool
+	mu       sync.Mutex
+}
+
+// entry is a recorded closer. The entries are kept in order of recording, an entry is removed when its closer
+// has been closed. A closing entry is being closed by Shutdown, so it's never closed again.
+type entry struct {
+	name    string
+	closer  io.Closer
+	closing bool
+}
+
+// New creates an empty Manager.
+func New() *Manager {
+	return &Manager{}
+}
+
+// Add records the closer made for the name. The same closer is recorded once, even if it's made for other names,
+// e.g. by a singleton factory. It returns ErrShutdown if Shutdown
+// has been started, the closer isn't recorded then and the caller should close it.
+func (m *Manager) Add(name string, closer io.Closer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if closer == nil {
+		return errors.New(name + " closer is nil")
+	}
+	if m.shutdown {
+		return ErrShutdown
+	}
+	for _, e := range m.entries {
+		if same(e.closer, closer) {
+			return nil
+		}
+	}
+	m.entries = append(m.entries, &entry{name: name, closer: closer})
+	return nil
+}
+
+// same reports whether the closers are the same instance. Closers which can't be compared, e.g. structs holding
+// a slice in an interface field, are never the same.
+func same(a io.Closer, b io.Closer) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() || !va.Comparable() || !vb.Comparable() {
+		return false
+	}
+	return va.Equal(vb)
+}
+
+// Len returns a number of recorded closers which haven't been closed yet, including the ones being closed.
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.entries)
+}
+
+// Shutdown closes every recorded closer in reverse order of recording, so a DAO is closed before the DAOs which
+// were created earlier and it may depend on. Consecutive closers of the same name are independent instances
+// of the same backend, so they're closed in parallel. If the context is done before all closers are closed then
+// the remaining closers aren't closed and the context error is returned.
+//
+// It returns errors of all closers joined by errors.Join. After Shutdown the Manager refuses new closers, the next
+// Shutdown calls only close closers which haven't been started because of the context. A closer which is being
+// closed, e.g. after the deadline or by a concurrent Shutdown, is never closed again.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.shutdown = true
+	claimed := make([]*entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		if !e.closing {
+			e.closing = true
+			claimed = append(claimed, e)
+		}
+	}
+	m.mu.Unlock()
+
+	errs := make([]error, 0)
+	for end := len(claimed); end > 0; {
+		start := end - 1
+		for start > 0 && claimed[start-1].name == claimed[end-1].name {
+			start--
+		}
+		run := claimed[start:end]
+		end = start
+
+		if err := ctx.Err(); err != nil {
+			m.release(run)
+			errs = append(errs, fmt.Errorf("%s isn't closed: %w", run[0].name, err))
+			continue
+		}
+		if err := m.closeRun(ctx, run); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeRun closes closers of the run in parallel. Every closer is forgotten when it's closed, even if it has
+// finished after the context is done.
+func (m *Manager) closeRun(ctx context.Context, run []*entry) error {
+	results := make(chan error, len(run))
+	for _, e := range run {
+		go func(e *entry) {
+			err := e.closer.Close()
+			m.forget(e)
+			results <- err
+		}(e)
+	}
+
+	errs := make([]error, 0)
+	for range run {
+		select {
+		case err := <-results:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", run[0].name, err))
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("%s isn't closed: %w", run[0].name, ctx.Err()))
+			return errors.Join(errs...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// release returns closers which haven't been started, so the next Shutdown closes them.
+func (m *Manager) release(run []*entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range run {
+		e.closing = false
+	}
+}
+
+func (m *Manager) forget(e *entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = slices.DeleteFunc(m.entries, func(recorded *entry) bool { return recorded == e })
+}
+
+// ShutdownOnSignal calls Shutdown when one of the signals is received or the context is done. If no signals are
+// passed then os.Interrupt and SIGTERM are handled. The timeout bounds Shutdown, if it is 0 then Shutdown isn't
+// bounded. The returned channel receives the Shutdown result and is closed after it.
+func (m *Manager) ShutdownOnSignal(ctx context.Context, timeout time.Duration, signals ...os.Signal) <-chan error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	notified, stop := signal.NotifyContext(ctx, signals...)
+
+	result := make(chan error, 1)
+	go func() {
+		defer close(result)
+		<-notified.Done()
+		stop()
+
+		shutdownCtx, cancel := context.WithCancel(context.Background())
+		if timeout > 0 {
+			shutdownCtx, cancel = context.WithTimeout(context.Background(), timeout)
+		}
+		defer cancel()
+		result <- m.Shutdown(shutdownCtx)
+	}()
+	return result
+}
+
+type trackedDAOFactory[K any, T any, F any] struct {
+	factory factory.DAOFactory[K, T, F]
+	manager *Manager
+}
+
+// Track returns a factory which records every DAO made by the factory in the Manager. If Shutdown has been started
+// then Make returns ErrShutdown, the made DAO isn't closed since it could be shared by the factory.
+func Track[K any, T any, F any](m *Manager, f factory.DAOFactory[K, T, F]) factory.DAOFactory[K, T, F] {
+	return &trackedDAOFactory[K, T, F]{factory: f, manager: m}
+}
+
+func (t *trackedDAOFactory[K, T, F]) Make(ctx context.Context, name string) (error, *dao.DAO[K, T, F]) {
+	err, d := t.factory.Make(ctx, name)
+	if err != nil {
+		return err, nil
+	}
+	if err := t.manager.Add(name, *d); err != nil {
+		return err, nil
+	}
+	return nil, d
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"reflect"
+	"sort"
 	"sync"
 )
 
 type Registry[T any] struct {
-	instances map[string]any
-	mu        sync.Mutex
+	instances   map[string]any
+	subscribers map[*subscriber[T]]struct{}
+	mu          sync.RWMutex
 }
 
 func New[T any](ctx context.Context) *Registry[T] {
@@ -29,26 +32,115 @@ func (r *Registry[T]) Register(name string, instance T) error {
 	}
 
 	r.instances[name] = instance
+	r.notify(Registered, name, instance, nil)
 	return nil
 }
 
 // Get returns an instance by name from the registry. It returns error if there is no an instance for the name.
 // Also, it can return error if the result instance has unexpected type.
 func (r *Registry[T]) Get(name string) (error, *T) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if instance, ok := r.instances[name]; ok {
+		return cast[T](name, instance)
+	}
+	return fmt.Errorf("%s instance hasn't found in registry", name), nil
+}
+
+// Unregister removes an instance by name from the registry, so the name could be registered again. It returns error
+// if there is no an instance for the name.
+func (r *Registry[T]) Unregister(name string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if instance, ok := r.instances[name]; ok {
+	previous, ok := r.instances[name]
+	if !ok {
+		return fmt.Errorf("%s instance hasn't found in registry", name)
+	}
+	delete(r.instances, name)
+	r.notify(Unregistered, name, nil, previous)
+	return nil
+}
+
+// Replace atomically swaps the registered instance with the new one and returns the previous instance. It returns
+// error if there is no an instance for the name, use Register for new names.
+func (r *Registry[T]) Replace(name string, instance T) (error, *T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.instances[name]
+	if !ok {
+		return fmt.Errorf("%s instance hasn't found in registry", name), nil
+	}
+	r.instances[name] = instance
+	r.notify(Replaced, name, instance, previous)
+	return cast[T](name, previous)
+}
 
-		if targetInst, casted := instance.(T); casted {
-			return nil, &targetInst
+// Names returns sorted names of all registered instances.
+func (r *Registry[T]) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.instances))
+	for name := range r.instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Len returns a number of registered instances.
+func (r *Registry[T]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.instances)
+}
+
+// Range calls fn for every registered instance in order of names until fn returns false. It iterates over
+// a snapshot, so fn could use the registry, e.g. unregister the instance.
+func (r *Registry[T]) Range(fn func(name string, instance T) bool) {
+	for name, instance := range r.All() {
+		if !fn(name, instance) {
+			return
 		}
-		return fmt.Errorf(
-			"%s instance has unexpected type %s, expected type %s",
-			name,
-			reflect.TypeOf(instance),
-			reflect.TypeOf((*T)(nil)),
-		), nil
 	}
-	return fmt.Errorf("%s instance hasn't found in registry", name), nil
+}
+
+// All returns an iterator over registered instances in order of names. Every iteration goes over a snapshot taken
+// at its start.
+func (r *Registry[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		r.mu.RLock()
+		names := make([]string, 0, len(r.instances))
+		snapshot := make(map[string]T, len(r.instances))
+		for name, instance := range r.instances {
+			if typed, ok := instance.(T); ok {
+				names = append(names, name)
+				snapshot[name] = typed
+			}
+		}
+		r.mu.RUnlock()
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !yield(name, snapshot[name]) {
+				return
+			}
+		}
+	}
+}
+
+func cast[T any](name string, instance any) (error, *T) {
+	if targetInst, casted := instance.(T); casted {
+		return nil, &targetInst
+	}
+	return fmt.Errorf(
+		"%s instance has unexpected type %s, expected type %s",
+		name,
+		reflect.TypeOf(instance),
+		reflect.TypeOf((*T)(nil)),
+	), nil
 }
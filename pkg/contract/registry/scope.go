@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+)
+
+// Scope bundles DAO and config producer registries. Scopes isolate names, e.g. per test or per tenant, and could
+// fall back to a parent scope for names they don't have. The package-level registries of config and dao packages
+// belong to the Global scope.
+type Scope struct {
+	parent  *Scope
+	daos    *Registry[any]
+	configs *Registry[any]
+}
+
+var global = NewScope(nil)
+
+type scopeKey struct{}
+
+// NewScope creates an empty scope. If parent isn't nil then names which aren't registered in the scope are resolved
+// by the parent.
+func NewScope(parent *Scope) *Scope {
+	return &Scope{
+		parent:  parent,
+		daos:    New[any](context.Background()),
+		configs: New[any](context.Background()),
+	}
+}
+
+// Global returns the process-wide scope used by the package-level registries.
+func Global() *Scope {
+	return global
+}
+
+// Parent returns the parent scope or nil.
+func (s *Scope) Parent() *Scope {
+	return s.parent
+}
+
+// DAOs returns the registry of DAO instances of the scope. It doesn't include the parent instances. It's untyped,
+// so DAOs should be registered by dao.RegisterScoped or dao.RegisterScopedProvider.
+func (s *Scope) DAOs() *Registry[any] {
+	return s.daos
+}
+
+// Configs returns the registry of config producers of the scope. It doesn't include the parent producers. It's
+// untyped, so producers should be registered by config.RegisterScoped.
+func (s *Scope) Configs() *Registry[any] {
+	return s.configs
+}
+
+// LookupDAO returns DAO instance by name from the scope or from the closest parent which has it.
+func (s *Scope) LookupDAO(name string) (error, any) {
+	return s.lookup(name, "dao", (*Scope).DAOs)
+}
+
+// LookupConfig returns config producer by name from the scope or from the closest parent which has it.
+func (s *Scope) LookupConfig(name string) (error, any) {
+	return s.lookup(name, "config", (*Scope).Configs)
+}
+
+func (s *Scope) lookup(name string, kind string, registry func(s *Scope) *Registry[any]) (error, any) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if err, instance := registry(scope).Get(name); err == nil {
+			return nil, *instance
+		}
+	}
+	return fmt.Errorf("%s %s instance hasn't found in scope", name, kind), nil
+}
+
+// WithScope returns a copy of the context which carries the scope.
+func WithScope(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// ScopeFrom returns the scope carried by the context. It returns the Global scope if the context has no scope.
+func ScopeFrom(ctx context.Context) *Scope {
+	if ctx != nil {
+		if s, ok := ctx.Value(scopeKey{}).(*Scope); ok && s != nil {
+			return s
+		}
+	}
+	return global
+}
@@ -0,0 +1,152 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// EventKind is a kind of registry change.
+type EventKind int
+
+// Registered means that a new name was registered.
+var Registered EventKind = 1
+
+// Replaced means that the instance of a name was replaced.
+var Replaced EventKind = 2
+
+// Unregistered means that a name was removed.
+var Unregistered EventKind = 3
+
+// String returns a name of the kind.
+func (k EventKind) String() string {
+	switch k {
+	case Registered:
+		return "registered"
+	case Replaced:
+		return "replaced"
+	case Unregistered:
+		return "unregistered"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event describes a registry change.
+type Event[T any] struct {
+
+	// Kind of the change.
+	Kind EventKind
+
+	// Name which was changed.
+	Name string
+
+	// Instance is a new instance. It's a zero value for Unregistered events.
+	Instance T
+
+	// Previous is a replaced or removed instance. It's a zero value for Registered events.
+	Previous T
+}
+
+// subscriber keeps undelivered events. The queue isn't bounded, so a slow subscriber never blocks the registry.
+type subscriber[T any] struct {
+	mu    sync.Mutex
+	queue []Event[T]
+	wake  chan struct{}
+}
+
+func (s *subscriber[T]) push(event Event[T]) {
+	s.mu.Lock()
+	s.queue = append(s.queue, event)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscriber[T]) take() []Event[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := s.queue
+	s.queue = nil
+	return events
+}
+
+// Subscribe calls fn for every change of the registry until the context is canceled. The calls are sequential and
+// follow the order of the changes. They're made by a separate goroutine, so fn may use the registry. The changes
+// made before Subscribe returns aren't delivered.
+func (r *Registry[T]) Subscribe(ctx context.Context, fn func(event Event[T])) {
+	r.subscribe(ctx, func(event Event[T]) bool {
+		fn(event)
+		return true
+	}, func() {})
+}
+
+// Watch returns a channel with every change of the registry in order of the changes. The channel is closed when
+// the context is canceled. The events are queued while the channel isn't read, so a slow reader never blocks
+// the registry.
+func (r *Registry[T]) Watch(ctx context.Context) <-chan Event[T] {
+	events := make(chan Event[T])
+	r.subscribe(ctx, func(event Event[T]) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(events) })
+	return events
+}
+
+func (r *Registry[T]) subscribe(ctx context.Context, deliver func(event Event[T]) bool, done func()) {
+	s := &subscriber[T]{wake: make(chan struct{}, 1)}
+
+	r.mu.Lock()
+	if r.subscribers == nil {
+		r.subscribers = map[*subscriber[T]]struct{}{}
+	}
+	r.subscribers[s] = struct{}{}
+	r.mu.Unlock()
+
+	go func() {
+		defer done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.subscribers, s)
+			r.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake:
+			}
+			for _, event := range s.take() {
+				if ctx.Err() != nil || !deliver(event) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// notify queues the event for every subscriber. It must be called under the write lock, so the events keep
+// the order of the changes.
+func (r *Registry[T]) notify(kind EventKind, name string, instance any, previous any) {
+	if len(r.subscribers) == 0 {
+		return
+	}
+	event := Event[T]{Kind: kind, Name: name}
+	if typed, ok := instance.(T); ok {
+		event.Instance = typed
+	}
+	if typed, ok := previous.(T); ok {
+		event.Previous = typed
+	}
+	for s := range r.subscribers {
+		s.push(event)
+	}
+}
@@ -0,0 +1,111 @@
+package httppage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SizeParam is a query parameter with a page size.
+const SizeParam = "page_size"
+
+// TokenParam is a query parameter with a next page token. It's mapped into Pagination.NextToken.
+const TokenParam = "page_token"
+
+// PrevTokenParam is a query parameter with a previous page token. It's mapped into Pagination.PrevToken.
+const PrevTokenParam = "prev_page_token"
+
+// TotalCountHeader is a response header with Pagination.Total.
+const TotalCountHeader = "X-Total-Count"
+
+// HasNextHeader is a response header with Pagination.HasNext.
+const HasNextHeader = "X-Has-Next"
+
+// Parse reads the request query parameters into Pagination. Tokens are expected to be base64url encoded without
+// padding as they're written by WriteHeaders. The absent parameters stay nil, so the result could be normalized
+// by dao.PaginationPolicy. It returns an error wrapping dao.ErrInvalidPagination if a parameter is malformed.
+func Parse(r *http.Request) (error, *dao.Pagination) {
+	query := r.URL.Query()
+	pagination := &dao.Pagination{}
+
+	if value := query.Get(SizeParam); value != "" {
+		size, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return fmt.Errorf("%w: %s should be a non-negative integer", dao.ErrInvalidPagination, SizeParam), nil
+		}
+		s := uint(size)
+		pagination.Size = &s
+	}
+
+	err, next := decodeParam(query, TokenParam)
+	if err != nil {
+		return err, nil
+	}
+	pagination.NextToken = next
+
+	err, prev := decodeParam(query, PrevTokenParam)
+	if err != nil {
+		return err, nil
+	}
+	pagination.PrevToken = prev
+	return nil, pagination
+}
+
+// WriteHeaders writes the response Pagination into headers: RFC 8288 Link header with "next" and "prev" relations
+// built from the request URL and the tokens, X-Total-Count from Total and X-Has-Next from HasNext. The "next" link
+// isn't written if HasNext is false. It must be called before the response body is written.
+func WriteHeaders(w http.ResponseWriter, r *http.Request, pagination *dao.Pagination) {
+	if pagination == nil {
+		return
+	}
+	header := w.Header()
+
+	links := make([]string, 0, 2)
+	if pagination.NextToken != nil && len(*pagination.NextToken) > 0 && (pagination.HasNext == nil || *pagination.HasNext) {
+		links = append(links, link(r, pagination, TokenParam, *pagination.NextToken, "next"))
+	}
+	if pagination.PrevToken != nil && len(*pagination.PrevToken) > 0 {
+		links = append(links, link(r, pagination, PrevTokenParam, *pagination.PrevToken, "prev"))
+	}
+	if len(links) > 0 {
+		header.Add("Link", strings.Join(links, ", "))
+	}
+
+	if pagination.Total != nil {
+		header.Set(TotalCountHeader, strconv.FormatUint(*pagination.Total, 10))
+	}
+	if pagination.HasNext != nil {
+		header.Set(HasNextHeader, strconv.FormatBool(*pagination.HasNext))
+	}
+}
+
+// link builds a link to the request URL with the token. The other query parameters are kept, the other token is
+// removed and the page size of the response is kept if it's known. The link is a relative reference.
+func link(r *http.Request, pagination *dao.Pagination, param string, token []byte, rel string) string {
+	query := r.URL.Query()
+	query.Del(TokenParam)
+	query.Del(PrevTokenParam)
+	query.Set(param, base64.RawURLEncoding.EncodeToString(token))
+	if pagination.Size != nil {
+		query.Set(SizeParam, strconv.FormatUint(uint64(*pagination.Size), 10))
+	}
+
+	target := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+}
+
+func decodeParam(query url.Values, param string) (error, *[]byte) {
+	value := query.Get(param)
+	if value == "" {
+		return nil, nil
+	}
+	token, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return fmt.Errorf("%w: %s isn't valid base64url", dao.ErrInvalidPagination, param), nil
+	}
+	return nil, &token
+}
@@ -0,0 +1,481 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// DefaultPageSize is a page size which is used when a request has Pagination without Size.
+const DefaultPageSize uint = 100
+
+var (
+	// ErrAlreadyExists is returned when an entity with the same key is already stored and upsert isn't requested.
+	ErrAlreadyExists = errors.New("entity already exists")
+
+	// ErrNotFound is returned when there is no entity for the key.
+	ErrNotFound = errors.New("entity hasn't found")
+
+	// ErrClosed is returned by every operation after Close has been called.
+	ErrClosed = errors.New("dao is closed")
+)
+
+// KeyFunc extracts a unique key from an entity.
+type KeyFunc[K comparable, T any] func(item T) K
+
+// MatchFunc reports whether an entity satisfies the filter. The filter is never nil.
+type MatchFunc[T any, F any] func(filter *F, item T) (error, bool)
+
+// MatchFilter is a MatchFunc for DAOs which use filter.Filter as a filter type. It evaluates the filter
+// by filter.Match.
+func MatchFilter[T any](f *filter.Filter, item T) (error, bool) {
+	return filter.Match(*f, item)
+}
+
+// Config is a configuration of the in-memory DAO. It could be passed into Configure as a value or a pointer,
+// any other config.Config is accepted and ignored.
+type Config struct {
+
+	// DefaultPageSize is used when a request Pagination doesn't define Size. If it is 0 then DefaultPageSize
+	// constant is taken.
+	DefaultPageSize uint
+
+	// MaxPageSize limits a page size of requests. It's optional. If it is 0 then the size isn't limited.
+	MaxPageSize uint
+}
+
+type inMemoryDAO[K comparable, T any, F any] struct {
+	key    KeyFunc[K, T]
+	match  MatchFunc[T, F]
+	items  map[K]T
+	order  []K
+	policy dao.PaginationPolicy
+	closed bool
+	mu     sync.RWMutex
+}
+
+// New creates a thread-safe in-memory DAO. The entities are kept in insertion order, so pagination is stable as
+// long as the data isn't changed between requests. The key function is required, the match function is optional and
+// if it is nil then a filter is ignored.
+func New[K comparable, T any, F any](key KeyFunc[K, T], match MatchFunc[T, F]) dao.DAO[K, T, F] {
+	return &inMemoryDAO[K, T, F]{
+		key:    key,
+		match:  match,
+		items:  map[K]T{},
+		order:  make([]K, 0),
+		policy: dao.PaginationPolicy{DefaultSize: DefaultPageSize},
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) Configure(ctx context.Context, cfg config.Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var c Config
+	switch v := cfg.(type) {
+	case Config:
+		c = v
+	case *Config:
+		if v != nil {
+			c = *v
+		}
+	}
+	if c.DefaultPageSize == 0 {
+		c.DefaultPageSize = DefaultPageSize
+	}
+	m.policy = dao.PaginationPolicy{DefaultSize: c.DefaultPageSize, MaxSize: c.MaxPageSize}
+	m.closed = false
+	return nil
+}
+
+func (m *inMemoryDAO[K, T, F]) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	return nil
+}
+
+func (m *inMemoryDAO[K, T, F]) Create(ctx context.Context, request *dao.CreateRequest[T]) (error, *dao.CreateResponse[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	err, created := m.put(request.Data, isTrue(request.Upsert), true)
+	if err != nil {
+		return err, nil
+	}
+
+	data := request.Data
+	updated := !created
+	return nil, &dao.CreateResponse[T]{
+		Data:    &data,
+		Created: &created,
+		Updated: &updated,
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) BulkCreate(ctx context.Context, request *dao.BulkCreateRequest[T]) (error, *dao.BulkCreateResponse[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	err, data, metadata := m.bulkPut(request.Data, isTrue(request.Upsert), true, isTrue(request.Partial))
+	if err != nil {
+		return err, nil
+	}
+	return nil, &dao.BulkCreateResponse[T]{
+		Data:     &data,
+		Metadata: metadata,
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) Read(ctx context.Context, request *dao.ReadRequest[F]) (error, *dao.ReadResponse[T]) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	err, data, pagination := m.find(request.Filter, request.Pagination)
+	if err != nil {
+		return err, nil
+	}
+	return nil, &dao.ReadResponse[T]{
+		Data:       data,
+		Pagination: pagination,
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) BulkRead(ctx context.Context, request *dao.BulkReadRequest[F]) (error, *dao.BulkReadResponse[T]) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	err, data, pagination := m.find(request.Filter, request.Pagination)
+	if err != nil {
+		return err, nil
+	}
+	return nil, &dao.BulkReadResponse[T]{
+		Data:       data,
+		Pagination: pagination,
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) RangeRead(ctx context.Context, request *dao.RangeReadRequest[F]) (error, *dao.RangeReadResponse[T]) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	err, data, pagination := m.find(request.Filer, request.Pagination)
+	if err != nil {
+		return err, nil
+	}
+	return nil, &dao.RangeReadResponse[T]{
+		Data:       data,
+		Pagination: pagination,
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) Update(ctx context.Context, request *dao.UpdateRequest[T]) (error, *dao.UpdateResponse[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	err, created := m.put(request.Data, true, isTrue(request.Upsert))
+	if err != nil {
+		return err, nil
+	}
+
+	data := request.Data
+	updated := !created
+	return nil, &dao.UpdateResponse[T]{
+		Data:    &data,
+		Created: &created,
+		Updated: &updated,
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) BulkUpdate(ctx context.Context, request *dao.BulkUpdateRequest[T]) (error, *dao.BulkUpdateResponse[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	err, data, metadata := m.bulkPut(request.Data, true, isTrue(request.Upsert), isTrue(request.Partial))
+	if err != nil {
+		return err, nil
+	}
+	return nil, &dao.BulkUpdateResponse[T]{
+		Data:     &data,
+		Metadata: metadata,
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) Delete(ctx context.Context, request *dao.DeleteRequest[K]) (error, *dao.DeleteResponse[K]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	if _, ok := m.items[request.Key]; !ok {
+		return fmt.Errorf("%w: %v", ErrNotFound, request.Key), nil
+	}
+	m.remove(request.Key)
+	return nil, &dao.DeleteResponse[K]{
+		Key: request.Key,
+	}
+}
+
+func (m *inMemoryDAO[K, T, F]) BulkDelete(ctx context.Context, request *dao.BulkDeleteRequest[K]) (error, *dao.BulkDeleteResponse[K]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.check(ctx); err != nil {
+		return err, nil
+	}
+
+	partial := isTrue(request.Partial)
+	if !partial {
+		for _, key := range request.Keys {
+			if _, ok := m.items[key]; !ok {
+				return fmt.Errorf("%w: %v", ErrNotFound, key), nil
+			}
+		}
+	}
+
+	deleted := make([]K, 0, len(request.Keys))
+	for _, key := range request.Keys {
+		if _, ok := m.items[key]; !ok {
+			continue
+		}
+		m.remove(key)
+		deleted = append(deleted, key)
+	}
+	return nil, &dao.BulkDeleteResponse[K]{
+		Deleted: &deleted,
+	}
+}
+
+// check verifies whether an operation could be started. It must be called under the lock.
+func (m *inMemoryDAO[K, T, F]) check(ctx context.Context) error {
+	if m.closed {
+		return ErrClosed
+	}
+	return ctx.Err()
+}
+
+// put stores the item. If the item exists then it's replaced only when replace is true. If the item doesn't exist
+// then it's inserted only when insert is true. It returns whether the item was created.
+func (m *inMemoryDAO[K, T, F]) put(item T, replace bool, insert bool) (error, bool) {
+	key := m.key(item)
+	if _, ok := m.items[key]; ok {
+		if !replace {
+			return fmt.Errorf("%w: %v", ErrAlreadyExists, key), false
+		}
+		m.items[key] = item
+		return nil, false
+	}
+
+	if !insert {
+		return fmt.Errorf("%w: %v", ErrNotFound, key), false
+	}
+	m.items[key] = item
+	m.order = append(m.order, key)
+	return nil, true
+}
+
+// bulkPut applies put for each item. If partial is false then the whole batch is validated before the first write,
+// so either all items are stored or none of them. Otherwise, the failed items are skipped and their errors
+// are reported through Metadata under "errors" key.
+func (m *inMemoryDAO[K, T, F]) bulkPut(items []T, replace bool, insert bool, partial bool) (error, []T, dao.Metadata) {
+	if !partial {
+		seen := make(map[K]struct{}, len(items))
+		for _, item := range items {
+			key := m.key(item)
+			_, exists := m.items[key]
+			if _, dup := seen[key]; dup {
+				exists = true
+			}
+			if exists && !replace {
+				return fmt.Errorf("%w: %v", ErrAlreadyExists, key), nil, nil
+			}
+			if !exists && !insert {
+				return fmt.Errorf("%w: %v", ErrNotFound, key), nil, nil
+			}
+			seen[key] = struct{}{}
+		}
+	}
+
+	data := make([]T, 0, len(items))
+	var errs []error
+	for _, item := range items {
+		if err, _ := m.put(item, replace, insert); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		data = append(data, item)
+	}
+
+	var metadata dao.Metadata
+	if len(errs) > 0 {
+		metadata = dao.Metadata{"errors": errs}
+	}
+	return nil, data, metadata
+}
+
+// remove deletes the key from the storage. It must be called under the write lock.
+func (m *inMemoryDAO[K, T, F]) remove(key K) {
+	delete(m.items, key)
+	for i, k := range m.order {
+		if k == key {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// find returns a page of matched items. A nil pagination is normalized like an empty one, so the default and
+// the maximum page sizes apply to it too. The total is counted according to the requested TotalMode, exact
+// by default. An estimated total stops the scan right after the page and extrapolates the matched ratio over
+// the whole storage, a skipped total stops the scan without counting.
+func (m *inMemoryDAO[K, T, F]) find(filter *F, request *dao.Pagination) (error, []T, *dao.Pagination) {
+	err, pagination := m.policy.Normalize(request)
+	if err != nil {
+		return err, nil, nil
+	}
+	err, offset, size := window(pagination)
+	if err != nil {
+		return err, nil, nil
+	}
+
+	mode := dao.TotalExact
+	if pagination.TotalMode != nil {
+		mode = *pagination.TotalMode
+	}
+	limit := -1
+	if mode != dao.TotalExact {
+		// One more item tells whether there is a next page. A limit beyond the storage size scans everything.
+		if n := uint(len(m.order)); offset < n && size < n-offset {
+			limit = int(offset + size + 1)
+		}
+	}
+	err, matched, scanned := m.scan(filter, limit)
+	if err != nil {
+		return err, nil, nil
+	}
+
+	start := min(offset, uint(len(matched)))
+	end := uint(len(matched))
+	if size < end-start {
+		end = start + size
+	}
+	hasNext := end < uint(len(matched))
+
+	response := &dao.Pagination{
+		Offset:    &start,
+		Size:      &size,
+		PrevToken: encodeToken(start),
+		HasNext:   &hasNext,
+	}
+	if hasNext {
+		response.NextToken = encodeToken(end)
+	}
+
+	switch {
+	case mode == dao.TotalExact || (mode == dao.TotalEstimated && scanned == len(m.order)):
+		total, kind := uint64(len(matched)), dao.TotalExact
+		response.Total, response.TotalKind = &total, &kind
+	case mode == dao.TotalEstimated:
+		total := uint64(float64(len(matched))*float64(len(m.order))/float64(scanned) + 0.5)
+		kind := dao.TotalEstimated
+		response.Total, response.TotalKind = &total, &kind
+	}
+	return nil, matched[start:end], response
+}
+
+// scan returns matched items in the insertion order and a number of scanned items. If limit isn't negative then
+// the scan stops after limit matched items.
+func (m *inMemoryDAO[K, T, F]) scan(filter *F, limit int) (error, []T, int) {
+	matched := make([]T, 0)
+	scanned := 0
+	for _, key := range m.order {
+		if limit >= 0 && len(matched) >= limit {
+			break
+		}
+		scanned++
+		item := m.items[key]
+		if filter != nil && m.match != nil {
+			err, ok := m.match(filter, item)
+			if err != nil {
+				return err, nil, 0
+			}
+			if !ok {
+				continue
+			}
+		}
+		matched = append(matched, item)
+	}
+	return nil, matched, scanned
+}
+
+// window resolves an offset and a size of the normalized pagination according to the priority rules: NextToken,
+// then PrevToken + Size, then Offset.
+func window(pagination *dao.Pagination) (error, uint, uint) {
+	size := *pagination.Size
+	switch {
+	case pagination.NextToken != nil:
+		err, offset := decodeToken(*pagination.NextToken)
+		return err, offset, size
+	case pagination.PrevToken != nil:
+		err, offset := decodeToken(*pagination.PrevToken)
+		if offset > math.MaxUint-size {
+			return err, math.MaxUint, size
+		}
+		return err, offset + size, size
+	}
+	return nil, *pagination.Offset, size
+}
+
+func encodeToken(offset uint) *[]byte {
+	token := []byte(strconv.FormatUint(uint64(offset), 10))
+	return &token
+}
+
+func decodeToken(token []byte) (error, uint) {
+	offset, err := strconv.ParseUint(string(token), 10, 0)
+	if err != nil {
+		return fmt.Errorf("invalid page token %q", token), 0
+	}
+	return nil, uint(offset)
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
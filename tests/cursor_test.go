@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/dao/cursor"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var cursorKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestCursorRoundTrip(t *testing.T) {
+	err, codec := cursor.NewCodec(cursorKey, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2024, 5, 1, 10, 0, 0, 5, time.UTC)
+	source := cursor.Cursor{
+		Offset:   42,
+		Keys:     []any{"USR1", -7, uint8(3), 1.5, true, created, []byte{1, 2}, nil},
+		Backward: true,
+	}
+	err, token := codec.Encode(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err, decoded := codec.Decode(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedKeys := []any{"USR1", int64(-7), uint64(3), 1.5, true, created, []byte{1, 2}, nil}
+	if decoded.Offset != 42 || !decoded.Backward || !reflect.DeepEqual(decoded.Keys, expectedKeys) {
+		t.Fatalf("unexpected cursor %+v", decoded)
+	}
+	if decoded.ExpiresAt.Before(time.Now()) || decoded.ExpiresAt.After(time.Now().Add(time.Hour)) {
+		t.Fatalf("unexpected expiration %v", decoded.ExpiresAt)
+	}
+}
+
+func TestCursorRejectsTokens(t *testing.T) {
+	_, codec := cursor.NewCodec(cursorKey, 0)
+	_, token := codec.Encode(cursor.Cursor{Offset: 10})
+
+	tampered := append([]byte{}, token...)
+	tampered[3] ^= 1
+	if err, _ := codec.Decode(tampered); !errors.Is(err, cursor.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	_, other := cursor.NewCodec([]byte("fedcba9876543210fedcba9876543210"), 0)
+	if err, _ := other.Decode(token); !errors.Is(err, cursor.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	wrongVersion := append([]byte{}, token...)
+	wrongVersion[0] = cursor.Version + 1
+	if err, _ := codec.Decode(wrongVersion); !errors.Is(err, cursor.ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	if err, _ := codec.Decode([]byte{cursor.Version}); !errors.Is(err, cursor.ErrMalformed) {
+		t.Fatalf("expected ErrMalformed, got %v", err)
+	}
+
+	_, expired := codec.Encode(cursor.Cursor{ExpiresAt: time.Now().Add(-time.Minute)})
+	if err, _ := codec.Decode(expired); !errors.Is(err, cursor.ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+
+	if err, _ := codec.Encode(cursor.Cursor{Keys: []any{struct{}{}}}); err == nil {
+		t.Fatal("expected error for unsupported key type")
+	}
+	if err, _ := cursor.NewCodec([]byte("short"), 0); err == nil {
+		t.Fatal("expected error for short key")
+	}
+}
+
+func TestCursorKeyRotation(t *testing.T) {
+	_, old := cursor.NewCodec(cursorKey, 0)
+	_, token := old.Encode(cursor.Cursor{Offset: 5})
+
+	newKey := []byte("fedcba9876543210fedcba9876543210")
+	_, rotated := cursor.NewCodec(newKey, 0, cursorKey)
+	err, decoded := rotated.Decode(token)
+	if err != nil || decoded.Offset != 5 {
+		t.Fatalf("previous key must verify old tokens, got %v %+v", err, decoded)
+	}
+
+	_, fresh := rotated.Encode(cursor.Cursor{Offset: 6})
+	if err, _ := old.Decode(fresh); !errors.Is(err, cursor.ErrInvalidSignature) {
+		t.Fatalf("new tokens must be signed by the new key, got %v", err)
+	}
+}
+
+func TestCursorTimeKeysOutsideNanosecondRange(t *testing.T) {
+	_, codec := cursor.NewCodec(cursorKey, 0)
+	times := []time.Time{
+		{},
+		time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1600, 6, 15, 12, 30, 0, 999999999, time.UTC),
+		time.Date(9999, 12, 31, 23, 59, 59, 1, time.UTC),
+	}
+	for _, tm := range times {
+		_, token := codec.Encode(cursor.Cursor{Keys: []any{tm}})
+		err, decoded := codec.Decode(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := decoded.Keys[0].(time.Time); !got.Equal(tm) {
+			t.Fatalf("expected %v, got %v", tm, got)
+		}
+	}
+}
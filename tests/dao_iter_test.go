@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"testing"
+)
+
+func newFilledAccountDAO(t *testing.T, n int) dao.DAO[string, Account, AccountFilter] {
+	d := newAccountDAO()
+	batch := make([]Account, 0, n)
+	for i := 0; i < n; i++ {
+		batch = append(batch, Account{ID: fmt.Sprintf("A%02d", i), Name: fmt.Sprintf("user%02d", i)})
+	}
+	if err, _ := d.BulkCreate(context.Background(), &dao.BulkCreateRequest[Account]{Data: batch}); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestAllWalksEveryPage(t *testing.T) {
+	d := newFilledAccountDAO(t, 7)
+	request := &dao.ReadRequest[AccountFilter]{Pagination: &dao.Pagination{Size: ptr(uint(3))}}
+
+	ids := make([]string, 0)
+	for account, err := range dao.All(context.Background(), d, request) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, account.ID)
+	}
+	if len(ids) != 7 || ids[0] != "A00" || ids[6] != "A06" {
+		t.Fatalf("unexpected ids %v", ids)
+	}
+	if request.Pagination.NextToken != nil {
+		t.Fatal("request must not be changed")
+	}
+
+	sizes := make([]int, 0)
+	for page, err := range dao.Pages(context.Background(), d, request) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(page.Data))
+	}
+	if fmt.Sprint(sizes) != "[3 3 1]" {
+		t.Fatalf("unexpected page sizes %v", sizes)
+	}
+}
+
+func TestAllWithFilterAndEarlyStop(t *testing.T) {
+	d := newFilledAccountDAO(t, 25)
+	request := &dao.BulkReadRequest[AccountFilter]{
+		Filter:     &AccountFilter{NamePrefix: "user1"},
+		Pagination: &dao.Pagination{Size: ptr(uint(4))},
+	}
+
+	count := 0
+	for _, err := range dao.BulkReadAll(context.Background(), d, request) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 10 {
+		t.Fatalf("expected 10 accounts, got %d", count)
+	}
+
+	count = 0
+	for range dao.RangeReadAll(context.Background(), d, &dao.RangeReadRequest[AccountFilter]{}) {
+		count++
+		if count == 5 {
+			break
+		}
+	}
+	if count != 5 {
+		t.Fatalf("expected early stop after 5 accounts, got %d", count)
+	}
+}
+
+func TestAllHonorsContextCancellation(t *testing.T) {
+	d := newFilledAccountDAO(t, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request := &dao.ReadRequest[AccountFilter]{Pagination: &dao.Pagination{Size: ptr(uint(2))}}
+
+	count := 0
+	var lastErr error
+	for _, err := range dao.All(ctx, d, request) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		count++
+		if count == 3 {
+			cancel()
+		}
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", lastErr)
+	}
+	if count != 4 {
+		t.Fatalf("expected the current page to be finished, got %d accounts", count)
+	}
+}
+
+func TestPagesKeepTotalMode(t *testing.T) {
+	d := newFilledAccountDAO(t, 7)
+	request := &dao.ReadRequest[AccountFilter]{Pagination: &dao.Pagination{Size: ptr(uint(3)), TotalMode: &dao.TotalSkipped}}
+
+	pages := 0
+	for response, err := range dao.Pages(context.Background(), d, request) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := response.Pagination; p.Total != nil || p.TotalKind != nil {
+			t.Fatalf("page %d must skip total, got %s", pages, *p.TotalKind)
+		}
+		pages++
+	}
+	if pages != 3 {
+		t.Fatalf("expected 3 pages, got %d", pages)
+	}
+}
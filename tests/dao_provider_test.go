@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/factory"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"github.com/hard-simple/go-dao/pkg/memory"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type accountDAO = dao.DAO[string, Account, AccountFilter]
+
+func TestProviderBuildsLazilyAndRetries(t *testing.T) {
+	name := "lazy-accounts"
+	var calls atomic.Int32
+	err := dao.RegisterProvider(name, func(ctx context.Context) (error, accountDAO) {
+		if calls.Add(1) == 1 {
+			return errors.New("backend isn't ready"), nil
+		}
+		return nil, newAccountDAO()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = dao.Unregister(name) })
+
+	if calls.Load() != 0 {
+		t.Fatal("provider must not be called on registration")
+	}
+	for registered := range dao.Instances() {
+		if registered == name {
+			t.Fatal("unbuilt dao must not be listed by Instances")
+		}
+	}
+
+	if err, _ := dao.GetDAO[accountDAO](name); err == nil || !strings.Contains(err.Error(), "isn't ready") {
+		t.Fatalf("expected provider error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	instances := make([]accountDAO, 8)
+	for i := range instances {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err, d := dao.GetDAO[accountDAO](name); err == nil {
+				instances[i] = *d
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, d := range instances {
+		if d == nil || d != instances[0] {
+			t.Fatal("every lookup must return the same instance")
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected one retry, got %d calls", calls.Load())
+	}
+}
+
+func TestProviderResolvedByFactory(t *testing.T) {
+	scope := registry.NewScope(nil)
+	ctx := registry.WithScope(context.Background(), scope)
+	built := false
+	_ = dao.RegisterScopedProvider(scope, "accounts", func(ctx context.Context) (error, accountDAO) {
+		built = true
+		return nil, newAccountDAO()
+	})
+	_ = config.RegisterScoped(scope, "accounts", func(ctx context.Context) config.Config {
+		return memory.Config{}
+	})
+
+	err, d := factory.NewSingletonDAOFactory[string, Account, AccountFilter](ctx).Make(ctx, "accounts")
+	if err != nil || !built || *d == nil {
+		t.Fatalf("factory must build the dao, got %v", err)
+	}
+}
+
+func TestProviderDetectsCircularDependencies(t *testing.T) {
+	scope := registry.NewScope(nil)
+	ctx := registry.WithScope(context.Background(), scope)
+	for _, pair := range [][2]string{{"orders", "customers"}, {"customers", "invoices"}, {"invoices", "orders"}} {
+		dependency := pair[1]
+		_ = dao.RegisterScopedProvider(scope, pair[0], func(ctx context.Context) (error, accountDAO) {
+			if err, _ := dao.GetDAOFromContext[accountDAO](ctx, dependency); err != nil {
+				return err, nil
+			}
+			return nil, newAccountDAO()
+		})
+	}
+
+	err, _ := dao.GetDAOFromContext[accountDAO](ctx, "orders")
+	if !errors.Is(err, dao.ErrCircularDependency) {
+		t.Fatalf("expected ErrCircularDependency, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "orders -> customers -> invoices -> orders") {
+		t.Fatalf("error must describe the cycle, got %v", err)
+	}
+}
+
+func TestProviderRetriesAfterPanic(t *testing.T) {
+	scope := registry.NewScope(nil)
+	ctx := registry.WithScope(context.Background(), scope)
+	var calls atomic.Int32
+	_ = dao.RegisterScopedProvider(scope, "accounts", func(ctx context.Context) (error, accountDAO) {
+		if calls.Add(1) == 1 {
+			panic("backend isn't ready")
+		}
+		return nil, newAccountDAO()
+	})
+
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = dao.GetDAOFromContext[accountDAO](ctx, "accounts")
+	}()
+	if err, d := dao.GetDAOFromContext[accountDAO](ctx, "accounts"); err != nil || *d == nil {
+		t.Fatalf("provider must be called again after a panic, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 provider calls, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"reflect"
+	"testing"
+)
+
+var accountSlot = dao.NewSlot[string, Account, AccountFilter]("slot-accounts")
+
+func TestSlotRegistersAndResolvesTypedDAO(t *testing.T) {
+	ctx := registry.WithScope(context.Background(), registry.NewScope(nil))
+	d := newAccountDAO()
+	if err := accountSlot.Register(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+
+	err, found := accountSlot.Get(ctx)
+	if err != nil || found != d {
+		t.Fatalf("unexpected dao %v", err)
+	}
+	if err, _ := accountSlot.Get(context.Background()); err == nil {
+		t.Fatal("slot registered in a scope must not be visible globally")
+	}
+
+	untyped := dao.NewSlot[int, Account, AccountFilter]("slot-accounts")
+	if err, _ := untyped.Get(ctx); err == nil {
+		t.Fatal("expected error for a slot of the same name with other key type")
+	}
+}
+
+func TestBackendsEnumeratesEntityTypes(t *testing.T) {
+	parent := registry.NewScope(nil)
+	child := registry.NewScope(parent)
+	parentCtx := registry.WithScope(context.Background(), parent)
+	ctx := registry.WithScope(context.Background(), child)
+
+	_ = accountSlot.Register(parentCtx, newAccountDAO())
+	_ = dao.NewSlot[string, Player, AccountFilter]("slot-players").RegisterProvider(ctx,
+		func(ctx context.Context) (error, dao.DAO[string, Player, AccountFilter]) { return nil, nil })
+	_ = child.DAOs().Register("untyped", "instance")
+
+	backends := dao.Backends(ctx)
+	if len(backends) != 2 {
+		t.Fatalf("expected 2 backends, got %+v", backends)
+	}
+	if backends[0] != accountSlot.Backend() || backends[0].Entity != reflect.TypeOf(Account{}) {
+		t.Fatalf("unexpected accounts backend %+v", backends[0])
+	}
+	if backends[1].Name != "slot-players" || backends[1].Entity != reflect.TypeOf(Player{}) || backends[1].Key.Kind() != reflect.String {
+		t.Fatalf("unexpected players backend %+v", backends[1])
+	}
+	if len(dao.Backends(parentCtx)) != 1 {
+		t.Fatal("parent scope must not see child backends")
+	}
+}
+
+func TestBackendsDropUnregisteredSlots(t *testing.T) {
+	scope := registry.NewScope(nil)
+	ctx := registry.WithScope(context.Background(), scope)
+	_ = accountSlot.Register(ctx, newAccountDAO())
+	if err := scope.DAOs().Unregister(accountSlot.Name()); err != nil {
+		t.Fatal(err)
+	}
+	if backends := dao.Backends(ctx); len(backends) != 0 {
+		t.Fatalf("unregistered slot must not be listed, got %+v", backends)
+	}
+
+	global := dao.NewSlot[string, Account, AccountFilter]("global-slot-accounts")
+	d := newAccountDAO()
+	if err := global.Register(context.Background(), d); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = dao.Unregister(global.Name()) })
+	for name, instance := range dao.Instances() {
+		if name == global.Name() && instance != d {
+			t.Fatalf("unexpected instance %v", instance)
+		}
+	}
+
+	if err, previous := dao.Replace(global.Name(), newAccountDAO()); err != nil || previous != d {
+		t.Fatalf("replace must return the slot instance, got %v %v", err, previous)
+	}
+	for _, backend := range dao.Backends(context.Background()) {
+		if backend.Name == global.Name() {
+			t.Fatal("slot replaced by an untyped instance must not be listed")
+		}
+	}
+}
@@ -25,6 +25,10 @@ func TestRunInMemoryUserDAO(t *testing.T) {
 	if err != nil {
 		panic(err)
 	}
+	t.Cleanup(func() {
+		_ = dao.Unregister(dbName)
+		_ = config.Unregister(dbName)
+	})
 
 	err = config.Register(dbName, func(ctx context.Context) config.Config {
 		maxBatchSize := os.Getenv("MAX_BATCH_SIZE")
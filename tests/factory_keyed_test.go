@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/factory"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// configurableAccountDAO counts Configure calls and blocks them until release is closed. It also records whether
+// Configure calls have overlapped.
+type configurableAccountDAO struct {
+	dao.DAO[string, Account, AccountFilter]
+	release    chan struct{}
+	configured atomic.Int32
+	running    atomic.Int32
+	overlapped atomic.Bool
+}
+
+func (c *configurableAccountDAO) Configure(ctx context.Context, cfg config.Config) error {
+	if c.running.Add(1) > 1 {
+		c.overlapped.Store(true)
+	}
+	defer c.running.Add(-1)
+	if c.release != nil {
+		<-c.release
+	}
+	c.configured.Add(1)
+	return c.DAO.Configure(ctx, cfg)
+}
+
+func registerConfigurable(t *testing.T, scope *registry.Scope, name string, release chan struct{}) *configurableAccountDAO {
+	d := &configurableAccountDAO{DAO: newAccountDAO(), release: release}
+	if err := dao.RegisterScoped(scope, name, dao.DAO[string, Account, AccountFilter](d)); err != nil {
+		t.Fatal(err)
+	}
+	_ = config.RegisterScoped(scope, name, func(ctx context.Context) config.Config { return nil })
+	return d
+}
+
+func TestKeyedFactoryMakesDAOPerName(t *testing.T) {
+	scope := registry.NewScope(nil)
+	ctx := registry.WithScope(context.Background(), scope)
+	primary := registerConfigurable(t, scope, "primary", nil)
+	archive := registerConfigurable(t, scope, "archive", nil)
+
+	f := factory.NewKeyedDAOFactory[string, Account, AccountFilter](ctx)
+	_, first := f.Make(ctx, "primary")
+	_, second := f.Make(ctx, "archive")
+	_, again := f.Make(ctx, "primary")
+	if *first != primary || *second != archive || again != first {
+		t.Fatal("factory must cache one dao per name")
+	}
+	if primary.configured.Load() != 1 {
+		t.Fatalf("dao must be configured once, got %d", primary.configured.Load())
+	}
+
+	f.Invalidate("primary")
+	_, _ = f.Make(ctx, "primary")
+	_, _ = f.Make(ctx, "archive")
+	if primary.configured.Load() != 2 || archive.configured.Load() != 1 {
+		t.Fatal("only the invalidated dao must be configured again")
+	}
+
+	f.InvalidateAll()
+	_, _ = f.Make(ctx, "archive")
+	if archive.configured.Load() != 2 {
+		t.Fatal("all daos must be configured again")
+	}
+
+	if err, _ := f.Make(ctx, "missing"); err == nil {
+		t.Fatal("expected error for unknown name")
+	}
+}
+
+func TestKeyedFactoryLocksPerName(t *testing.T) {
+	scope := registry.NewScope(nil)
+	ctx := registry.WithScope(context.Background(), scope)
+	release := make(chan struct{})
+	registerConfigurable(t, scope, "slow", release)
+	registerConfigurable(t, scope, "fast", nil)
+
+	f := factory.NewKeyedDAOFactory[string, Account, AccountFilter](ctx)
+	slowDone := make(chan error)
+	go func() {
+		err, _ := f.Make(ctx, "slow")
+		slowDone <- err
+	}()
+
+	fastDone := make(chan error)
+	go func() {
+		err, _ := f.Make(ctx, "fast")
+		fastDone <- err
+	}()
+	select {
+	case err := <-fastDone:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow Configure must not block other names")
+	}
+
+	close(release)
+	if err := <-slowDone; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyedFactoryMakesDAOPerScope(t *testing.T) {
+	first, second := registry.NewScope(nil), registry.NewScope(nil)
+	firstCtx := registry.WithScope(context.Background(), first)
+	secondCtx := registry.WithScope(context.Background(), second)
+	firstDAO := registerConfigurable(t, first, "tenant", nil)
+	secondDAO := registerConfigurable(t, second, "tenant", nil)
+
+	f := factory.NewKeyedDAOFactory[string, Account, AccountFilter](context.Background())
+	_, fromFirst := f.Make(firstCtx, "tenant")
+	_, fromSecond := f.Make(secondCtx, "tenant")
+	if *fromFirst != firstDAO || *fromSecond != secondDAO {
+		t.Fatal("factory must not share cached daos between scopes")
+	}
+
+	f.Invalidate("tenant")
+	_, _ = f.Make(firstCtx, "tenant")
+	_, _ = f.Make(secondCtx, "tenant")
+	if firstDAO.configured.Load() != 2 || secondDAO.configured.Load() != 2 {
+		t.Fatal("invalidated name must be configured again in every scope")
+	}
+}
+
+func TestKeyedFactoryInvalidateKeepsPerNameLock(t *testing.T) {
+	scope := registry.NewScope(nil)
+	ctx := registry.WithScope(context.Background(), scope)
+	release := make(chan struct{})
+	d := registerConfigurable(t, scope, "slow", release)
+
+	f := factory.NewKeyedDAOFactory[string, Account, AccountFilter](ctx)
+	var wg sync.WaitGroup
+	makeSlow := func() {
+		defer wg.Done()
+		_, _ = f.Make(ctx, "slow")
+	}
+	wg.Add(1)
+	go makeSlow()
+	for d.running.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		f.Invalidate("slow")
+	}()
+	go makeSlow()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if d.overlapped.Load() {
+		t.Fatal("invalidated dao must not be configured concurrently")
+	}
+}
+
+func TestKeyedFactoryInvalidateScope(t *testing.T) {
+	first, second := registry.NewScope(nil), registry.NewScope(nil)
+	firstCtx := registry.WithScope(context.Background(), first)
+	secondCtx := registry.WithScope(context.Background(), second)
+	firstDAO := registerConfigurable(t, first, "tenant", nil)
+	secondDAO := registerConfigurable(t, second, "tenant", nil)
+
+	f := factory.NewKeyedDAOFactory[string, Account, AccountFilter](context.Background())
+	_, _ = f.Make(firstCtx, "tenant")
+	_, _ = f.Make(secondCtx, "tenant")
+	f.InvalidateScope(first)
+	_, _ = f.Make(firstCtx, "tenant")
+	_, _ = f.Make(secondCtx, "tenant")
+	if firstDAO.configured.Load() != 2 || secondDAO.configured.Load() != 1 {
+		t.Fatal("only daos of the invalidated scope must be configured again")
+	}
+}
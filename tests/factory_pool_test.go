@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/factory"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackedAccountDAO reports whether it was closed.
+type trackedAccountDAO struct {
+	dao.DAO[string, Account, AccountFilter]
+	id     int32
+	closed atomic.Bool
+}
+
+func (d *trackedAccountDAO) Close() error {
+	d.closed.Store(true)
+	return d.DAO.Close()
+}
+
+func newTrackedScope(t *testing.T, name string) (context.Context, *atomic.Int32) {
+	scope := registry.NewScope(nil)
+	built := &atomic.Int32{}
+	err := dao.RegisterScopedProvider(scope, name, func(ctx context.Context) (error, dao.DAO[string, Account, AccountFilter]) {
+		return nil, &trackedAccountDAO{DAO: newAccountDAO(), id: built.Add(1)}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = config.RegisterScoped(scope, name, func(ctx context.Context) config.Config { return nil })
+	return registry.WithScope(context.Background(), scope), built
+}
+
+func tracked(d *dao.DAO[string, Account, AccountFilter]) *trackedAccountDAO {
+	return (*d).(*trackedAccountDAO)
+}
+
+func TestPrototypeFactoryBuildsNewDAO(t *testing.T) {
+	ctx, built := newTrackedScope(t, "accounts")
+	f := factory.NewPrototypeDAOFactory[string, Account, AccountFilter](ctx)
+
+	_, first := f.Make(ctx, "accounts")
+	_, second := f.Make(ctx, "accounts")
+	if built.Load() != 2 || tracked(first) == tracked(second) {
+		t.Fatal("every Make must build a new dao")
+	}
+
+	_ = dao.Register("prototype-instance", newAccountDAO())
+	t.Cleanup(func() { _ = dao.Unregister("prototype-instance") })
+	_ = config.Register("prototype-instance", func(ctx context.Context) config.Config { return nil })
+	t.Cleanup(func() { _ = config.Unregister("prototype-instance") })
+	if err, _ := f.Make(context.Background(), "prototype-instance"); err == nil {
+		t.Fatal("expected error for dao registered without provider")
+	}
+}
+
+func TestPooledFactoryLendsAndReuses(t *testing.T) {
+	ctx, built := newTrackedScope(t, "accounts")
+	pool := factory.NewPooledDAOFactory(ctx, factory.PoolConfig[string, Account, AccountFilter]{MaxSize: 2})
+
+	_, first := pool.Acquire(ctx, "accounts")
+	_, second := pool.Acquire(ctx, "accounts")
+	if tracked(first) == tracked(second) {
+		t.Fatal("lent daos must be different")
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err, _ := pool.Acquire(timeout, "accounts"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected to wait for a released dao, got %v", err)
+	}
+
+	acquired := make(chan *dao.DAO[string, Account, AccountFilter])
+	go func() {
+		_, d := pool.Acquire(ctx, "accounts")
+		acquired <- d
+	}()
+	if err := pool.Release(first); err != nil {
+		t.Fatal(err)
+	}
+	third := <-acquired
+	if tracked(third) != tracked(first) || built.Load() != 2 {
+		t.Fatal("released dao must be reused")
+	}
+
+	if err := pool.Release(third); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Release(third); err == nil {
+		t.Fatal("expected error for releasing dao twice")
+	}
+
+	_ = pool.Release(second)
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !tracked(first).closed.Load() || !tracked(second).closed.Load() {
+		t.Fatal("idle daos must be closed with the pool")
+	}
+	if err, _ := pool.Acquire(ctx, "accounts"); !errors.Is(err, factory.ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPooledFactoryEvictsIdleAndUnhealthy(t *testing.T) {
+	ctx, built := newTrackedScope(t, "accounts")
+	unhealthy := atomic.Int32{}
+	pool := factory.NewPooledDAOFactory(ctx, factory.PoolConfig[string, Account, AccountFilter]{
+		IdleTimeout: 20 * time.Millisecond,
+		HealthCheck: func(ctx context.Context, d dao.DAO[string, Account, AccountFilter]) error {
+			if d.(*trackedAccountDAO).id == unhealthy.Load() {
+				return errors.New("connection is lost")
+			}
+			return nil
+		},
+	})
+
+	_, first := pool.Acquire(ctx, "accounts")
+	_ = pool.Release(first)
+	time.Sleep(40 * time.Millisecond)
+	_, second := pool.Acquire(ctx, "accounts")
+	if !tracked(first).closed.Load() || tracked(second).id != 2 {
+		t.Fatal("expired idle dao must be closed and replaced")
+	}
+
+	unhealthy.Store(2)
+	_ = pool.Release(second)
+	_, third := pool.Acquire(ctx, "accounts")
+	if !tracked(second).closed.Load() || tracked(third).id != 3 || built.Load() != 3 {
+		t.Fatal("unhealthy dao must be closed and replaced")
+	}
+	_ = pool.Release(third)
+	_ = pool.Close()
+}
+
+func TestPooledFactoryEvictsIdleWithoutCalls(t *testing.T) {
+	ctx, _ := newTrackedScope(t, "accounts")
+	pool := factory.NewPooledDAOFactory(ctx, factory.PoolConfig[string, Account, AccountFilter]{
+		IdleTimeout: 10 * time.Millisecond,
+	})
+	defer func() { _ = pool.Close() }()
+
+	_, d := pool.Acquire(ctx, "accounts")
+	_ = pool.Release(d)
+	deadline := time.Now().Add(time.Second)
+	for !tracked(d).closed.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("idle dao must be evicted by the pool itself")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterJSONRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 30, 0, 123, time.UTC)
+	source := filter.New().
+		AddField(field("status", filter.Eq, "active")).
+		AddField(field("deleted", filter.Eq, nil)).
+		And(filter.New().Or(
+			filter.New().AddField(field("id", filter.Eq, uint64(1<<63))),
+			filter.New().AddField(field("name", filter.RegEx, regexp.MustCompile("^J"))),
+		)).
+		Not(filter.New().
+			AddField(field("age", filter.Between, []int{0, 17})).
+			AddField(field("created", filter.Gt, created)).
+			AddField(field("ttl", filter.Lt, 5*time.Minute)).
+			AddField(field("mixed", filter.Eq, []any{"a", 1.5, true})))
+
+	err, data := filter.MarshalJSON(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"version":1`) || !strings.Contains(string(data), `"op":"between","code":9`) {
+		t.Fatalf("unexpected document %s", data)
+	}
+
+	err, decoded := filter.UnmarshalJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := decoded.GetFields()
+	if len(fields) != 2 || fields[0].Expression().Value() != "active" || fields[1].Expression().Value() != nil {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+
+	or := decoded.GetAnd()[0].GetOr()
+	if or[0].GetFields()[0].Expression().Value() != uint64(1<<63) {
+		t.Fatalf("unexpected id %v", or[0].GetFields()[0].Expression().Value())
+	}
+	if or[1].GetFields()[0].Expression().Value() != "^J" || or[1].GetFields()[0].Expression().Op() != filter.RegEx {
+		t.Fatal("unexpected regular expression")
+	}
+
+	not := decoded.GetNot()[0].GetFields()
+	expected := []any{[]int{0, 17}, created, 5 * time.Minute, []any{"a", 1.5, true}}
+	for i, value := range expected {
+		if !reflect.DeepEqual(not[i].Expression().Value(), value) {
+			t.Errorf("unexpected value %#v, expected %#v", not[i].Expression().Value(), value)
+		}
+	}
+
+	err, again := filter.MarshalJSON(decoded)
+	if err != nil || string(again) != string(data) {
+		t.Fatalf("second round trip differs:\n%s\n%s", again, data)
+	}
+}
+
+func TestFilterJSONDecodeErrors(t *testing.T) {
+	invalid := []string{
+		`{"version":2,"filter":{}}`,
+		`{"version":1,"filter":{"fields":[{"name":"a","op":"unknown"}]}}`,
+		`{"version":1,"filter":{"fields":[{"name":"a","op":"eq","code":4}]}}`,
+		`{"version":1,"filter":{"fields":[{"name":"a","code":100}]}}`,
+		`{"version":1,"filter":{"fields":[{"name":"a"}]}}`,
+		`{"version":1,"filter":{"fields":[{"name":"a","op":"eq","value":{"type":"int8","value":300}}]}}`,
+		`{"version":1,"filter":{"fields":[{"name":"a","op":"eq","value":{"type":"object","value":{}}}]}}`,
+		`{"version":1,"filter":{"and":[null]}}`,
+	}
+	for _, doc := range invalid {
+		if err, _ := filter.UnmarshalJSON([]byte(doc)); err == nil {
+			t.Errorf("expected error for %s", doc)
+		}
+	}
+
+	err, f := filter.UnmarshalJSON([]byte(`{"version":1,"filter":{"fields":[{"name":"a","code":2,"value":{"type":"string","value":"x"}}]}}`))
+	if err != nil || f.GetFields()[0].Expression().Op() != filter.Contains {
+		t.Fatalf("operation code must be accepted without name, got %v", err)
+	}
+
+	if err, _ := filter.MarshalJSON(filter.New().AddField(field("a", filter.Eq, struct{}{}))); err == nil {
+		t.Fatal("expected error for unsupported value type")
+	}
+}
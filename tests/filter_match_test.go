@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"github.com/hard-simple/go-dao/pkg/memory"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type Address struct {
+	City string `dao:"city"`
+}
+
+type Customer struct {
+	ID       string    `dao:"id"`
+	FullName string    `dao:"name"`
+	Status   string    `dao:"status"`
+	Age      int       `dao:"age"`
+	Score    float64   `dao:"score"`
+	Tags     []string  `dao:"tags"`
+	Created  time.Time `dao:"created"`
+	Address  *Address  `dao:"address"`
+	Secret   string    `dao:"-"`
+	Nickname string
+}
+
+func field(name string, op filter.Operation, value any) filter.FieldExpression {
+	return filter.NewFieldExpression(name, filter.NewExpression(op, value))
+}
+
+func TestFilterMatchOperations(t *testing.T) {
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	c := Customer{
+		ID:       "USR1",
+		FullName: "John Smith",
+		Status:   "active",
+		Age:      30,
+		Score:    4.5,
+		Tags:     []string{"vip", "beta"},
+		Created:  created,
+		Address:  &Address{City: "Kyiv"},
+		Nickname: "js",
+	}
+
+	cases := []struct {
+		name     string
+		fe       filter.FieldExpression
+		expected bool
+	}{
+		{"eq", field("id", filter.Eq, "USR1"), true},
+		{"eq untagged", field("nickname", filter.Eq, "js"), true},
+		{"eq numeric kinds", field("age", filter.Eq, int64(30)), true},
+		{"contains string", field("name", filter.Contains, "Smith"), true},
+		{"contains slice", field("tags", filter.Contains, "vip"), true},
+		{"contains slice miss", field("tags", filter.Contains, "alpha"), false},
+		{"start with", field("name", filter.StartWith, "John"), true},
+		{"gt", field("age", filter.Gt, 29), true},
+		{"gte", field("age", filter.Gte, 30), true},
+		{"lt float", field("score", filter.Lt, 5), true},
+		{"lte", field("age", filter.Lte, uint(29)), false},
+		{"regex string", field("name", filter.RegEx, "^J.*h$"), true},
+		{"regex compiled", field("name", filter.RegEx, regexp.MustCompile("^Smith")), false},
+		{"between", field("age", filter.Between, []int{18, 30}), true},
+		{"between time", field("created", filter.Between, []time.Time{created.Add(-time.Hour), created}), true},
+		{"gt time", field("created", filter.Gt, created), false},
+		{"nested", field("address.city", filter.Eq, "Kyiv"), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err, ok := filter.Match(filter.New().AddField(tc.fe), &c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, ok)
+			}
+		})
+	}
+}
+
+func TestFilterMatchTree(t *testing.T) {
+	c := Customer{ID: "USR2", FullName: "John", Status: "active", Age: 17}
+
+	f := filter.New().
+		AddField(field("status", filter.Eq, "active")).
+		And(filter.New().Or(
+			filter.New().AddField(field("id", filter.Eq, "USR1")),
+			filter.New().AddField(field("name", filter.Contains, "John")),
+		)).
+		Not(filter.New().AddField(field("age", filter.Lt, 18)))
+
+	err, ok := filter.Match(f, c)
+	if err != nil || ok {
+		t.Fatalf("expected not matched, got %v %v", ok, err)
+	}
+
+	c.Age = 18
+	err, ok = filter.Match(f, c)
+	if err != nil || !ok {
+		t.Fatalf("expected matched, got %v %v", ok, err)
+	}
+
+	err, ok = filter.Match(filter.New().AddField(field("address.city", filter.Eq, "Kyiv")), c)
+	if err != nil || ok {
+		t.Fatalf("nil nested field must not match, got %v %v", ok, err)
+	}
+}
+
+func TestFilterMatchErrors(t *testing.T) {
+	c := Customer{Age: 30, Secret: "s"}
+
+	invalid := []filter.FieldExpression{
+		field("unknown", filter.Eq, 1),
+		field("secret", filter.Eq, "s"),
+		field("age", filter.Gt, "thirty"),
+		field("age", filter.StartWith, "3"),
+		field("name", filter.RegEx, "("),
+		field("age", filter.Between, []int{1}),
+		field("age", filter.Undefined, nil),
+	}
+	for _, fe := range invalid {
+		if err, _ := filter.Match(filter.New().AddField(fe), c); err == nil {
+			t.Errorf("expected error for %s", fe.Name())
+		}
+	}
+}
+
+func TestMemoryWithFilterMatch(t *testing.T) {
+	ctx := context.Background()
+	d := memory.New[string, Customer, filter.Filter](
+		func(c Customer) string { return c.ID },
+		memory.MatchFilter[Customer],
+	)
+	_, _ = d.BulkCreate(ctx, &dao.BulkCreateRequest[Customer]{Data: []Customer{
+		{ID: "1", Age: 10}, {ID: "2", Age: 20}, {ID: "3", Age: 30},
+	}})
+
+	f := filter.New().AddField(field("age", filter.Gte, 20))
+	err, resp := d.Read(ctx, &dao.ReadRequest[filter.Filter]{Filter: &f})
+	if err != nil || len(resp.Data) != 2 {
+		t.Fatalf("unexpected read result: %v %+v", err, resp)
+	}
+}
+
+func TestFilterMatchContainsUnhashableMapKey(t *testing.T) {
+	item := map[string]any{"counts": map[any]int{"a": 1}}
+	err, _ := filter.Match(filter.New().AddField(field("counts", filter.Contains, []int{1})), item)
+	if err == nil {
+		t.Fatal("expected error for unhashable map key")
+	}
+	if err, ok := filter.Match(filter.New().AddField(field("counts", filter.Contains, "a")), item); err != nil || !ok {
+		t.Fatalf("expected match, got %v %v", err, ok)
+	}
+}
@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"github.com/hard-simple/go-dao/pkg/contract/filter/mongofilter"
+	"github.com/hard-simple/go-dao/pkg/contract/filter/query"
+	"github.com/hard-simple/go-dao/pkg/contract/filter/sqlfilter"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	geoWithinOnce sync.Once
+	geoWithin     filter.Operation
+	jsonPathOnce  sync.Once
+	jsonPath      filter.Operation
+)
+
+func registerGeoWithin(t *testing.T) filter.Operation {
+	geoWithinOnce.Do(func() {
+		err, op := filter.RegisterOperation(1001, "geoWithin", filter.Variadic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		geoWithin = op
+	})
+	return geoWithin
+}
+
+func registerJSONPathExists(t *testing.T) filter.Operation {
+	jsonPathOnce.Do(func() {
+		err, op := filter.RegisterOperation(1002, "jsonPathExists", filter.Nullary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonPath = op
+	})
+	return jsonPath
+}
+
+func TestRegisterOperation(t *testing.T) {
+	op := registerGeoWithin(t)
+
+	if err, byCode := filter.ToOperation(1001); err != nil || byCode != op {
+		t.Fatalf("unexpected lookup by code: %v %v", byCode, err)
+	}
+	if err, byName := filter.ParseOperation("geoWithin"); err != nil || byName != op {
+		t.Fatalf("unexpected lookup by name: %v %v", byName, err)
+	}
+	if op.String() != "geoWithin" || op.Arity() != filter.Variadic || !op.Registered() {
+		t.Fatalf("unexpected operation info %s %d", op, op.Arity())
+	}
+	if filter.Between.Arity() != filter.Binary || filter.Eq.Arity() != filter.Unary {
+		t.Fatal("unexpected built-in arity")
+	}
+
+	collisions := []struct {
+		code int
+		name string
+	}{
+		{1001, "other"},
+		{filter.FromOperation(filter.Eq), "equals"},
+		{1500, "eq"},
+		{1501, ""},
+	}
+	for _, c := range collisions {
+		if err, _ := filter.RegisterOperation(c.code, c.name); err == nil {
+			t.Errorf("expected collision error for %d %q", c.code, c.name)
+		}
+	}
+	if err, _ := filter.RegisterOperation(1502, "twoArities", filter.Unary, filter.Binary); err == nil {
+		t.Error("expected error for several arities")
+	}
+}
+
+func TestCustomOperationSupport(t *testing.T) {
+	geo := registerGeoWithin(t)
+	exists := registerJSONPathExists(t)
+
+	f := filter.New().
+		AddField(field("status", filter.Eq, "active")).
+		Or(filter.New().AddField(field("location", geo, []float64{1, 2})))
+
+	if err := filter.CheckSupported(f, filter.SupporterFunc(filter.MatchSupports)); err == nil ||
+		!strings.Contains(err.Error(), "geoWithin") {
+		t.Fatalf("expected unsupported geoWithin, got %v", err)
+	}
+	if err := filter.CheckSupported(f, sqlfilter.New(sqlfilter.PostgreSQL, nil)); err == nil {
+		t.Fatal("expected unsupported operation for sql compiler")
+	}
+	if err := filter.CheckSupported(f, mongofilter.Translator{}); err == nil {
+		t.Fatal("expected unsupported operation for mongo translator")
+	}
+	for _, op := range filter.BuiltinOperations() {
+		if !filter.MatchSupports(op) || !mongofilter.Supports(op) || op == filter.Undefined {
+			t.Fatalf("built-in operation %s must be supported", op)
+		}
+	}
+	if filter.IsBuiltin(geo) || filter.IsBuiltin(filter.Undefined) {
+		t.Fatal("custom and undefined operations aren't built-in")
+	}
+	supportsAll := filter.SupporterFunc(func(op filter.Operation) bool { return true })
+	if err := filter.CheckSupported(f, supportsAll); err != nil {
+		t.Fatal(err)
+	}
+	if err, _ := filter.Match(f, Customer{Status: "active"}); err == nil || !strings.Contains(err.Error(), "geoWithin") {
+		t.Fatalf("expected match error naming the operation, got %v", err)
+	}
+
+	err, data := filter.MarshalJSON(f)
+	if err != nil || !strings.Contains(string(data), `"op":"geoWithin","code":1001`) {
+		t.Fatalf("unexpected json %s %v", data, err)
+	}
+	err, decoded := filter.UnmarshalJSON(data)
+	if err != nil || decoded.GetOr()[0].GetFields()[0].Expression().Op() != geo {
+		t.Fatalf("custom operation must survive json round trip: %v", err)
+	}
+
+	text := `location geoWithin [1.0, 2.0] AND doc jsonPathExists`
+	err, parsed := query.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.GetFields()[1].Expression().Op() != exists || query.Format(parsed) != text {
+		t.Fatalf("unexpected query round trip %s", query.Format(parsed))
+	}
+}
+
+func TestExtendedOperationsMatch(t *testing.T) {
+	c := Customer{ID: "USR1", FullName: "John Smith", Status: "active", Tags: []string{"vip"}}
+	doc := map[string]any{"status": "active", "deletedAt": nil}
+
+	cases := []struct {
+		name     string
+		fe       filter.FieldExpression
+		value    any
+		expected bool
+	}{
+		{"ne", field("status", filter.Ne, "blocked"), c, true},
+		{"ne same", field("status", filter.Ne, "active"), c, false},
+		{"ne null", field("address", filter.Ne, nil), c, false},
+		{"in", field("status", filter.In, []string{"active", "new"}), c, true},
+		{"not in", field("status", filter.NotIn, []string{"active", "new"}), c, false},
+		{"in null", field("address", filter.In, []any{nil}), c, true},
+		{"is null", field("address", filter.IsNull, nil), c, true},
+		{"is null missing key", field("updatedAt", filter.IsNull, nil), doc, true},
+		{"exists", field("deletedAt", filter.Exists, nil), doc, true},
+		{"exists missing key", field("updatedAt", filter.Exists, nil), doc, false},
+		{"ends with", field("name", filter.EndsWith, "Smith"), c, true},
+		{"eq ignore case", field("name", filter.EqIgnoreCase, "JOHN SMITH"), c, true},
+		{"contains ignore case", field("name", filter.ContainsIgnoreCase, "n sM"), c, true},
+		{"start with ignore case", field("name", filter.StartWithIgnoreCase, "jo"), c, true},
+		{"ends with ignore case", field("name", filter.EndsWithIgnoreCase, "SMITH"), c, true},
+		{"ends with ignore case miss", field("name", filter.EndsWithIgnoreCase, "john"), c, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err, ok := filter.Match(filter.New().AddField(tc.fe), tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, ok)
+			}
+		})
+	}
+
+	if err, _ := filter.Match(filter.New().AddField(field("status", filter.In, "active")), c); err == nil {
+		t.Fatal("expected error for non-list value")
+	}
+}
+
+func TestExtendedOperationsTranslation(t *testing.T) {
+	f := filter.New().
+		AddField(field("status", filter.NotIn, []string{"blocked", "deleted"})).
+		AddField(field("id", filter.In, []string{})).
+		AddField(field("name", filter.EndsWithIgnoreCase, "_x")).
+		AddField(field("age", filter.IsNull, nil)).
+		AddField(field("status", filter.Ne, "new"))
+
+	err, where, args := sqlfilter.New(sqlfilter.PostgreSQL, userColumns).Compile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `"u"."status" NOT IN ($1, $2) AND 1 = 0 AND LOWER("u"."name") LIKE LOWER($3) ESCAPE '!'` +
+		` AND "u"."age" IS NULL AND "u"."status" <> $4`
+	if where != expected || len(args) != 4 || args[2] != "%!_x" {
+		t.Fatalf("unexpected sql %s %v", where, args)
+	}
+
+	err, doc := mongofilter.Translate(filter.New().
+		AddField(field("name", filter.EqIgnoreCase, "a.b")).
+		AddField(field("deletedAt", filter.Exists, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	and := doc["$and"].([]any)
+	name := and[0].(mongofilter.Document)["name"].(mongofilter.Document)
+	if name["$regex"] != `^a\.b$` || name["$options"] != "i" {
+		t.Fatalf("unexpected document %v", doc)
+	}
+	if and[1].(mongofilter.Document)["deletedAt"].(mongofilter.Document)["$exists"] != true {
+		t.Fatalf("unexpected document %v", doc)
+	}
+
+	text := `status NOT IN ["a", "b"] AND id IN [1] AND name $= "x" AND deletedAt IS NULL AND status != "new"` +
+		` AND name eqIgnoreCase "JOHN" AND updatedAt exists`
+	err, parsed := query.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := []filter.Operation{filter.NotIn, filter.In, filter.EndsWith, filter.IsNull, filter.Ne, filter.EqIgnoreCase, filter.Exists}
+	for i, fe := range parsed.GetFields() {
+		if fe.Expression().Op() != ops[i] {
+			t.Errorf("unexpected operation %s for %s", fe.Expression().Op(), fe.Name())
+		}
+	}
+	if query.Format(parsed) != text {
+		t.Fatalf("unexpected format %s", query.Format(parsed))
+	}
+}
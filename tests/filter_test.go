@@ -78,3 +78,40 @@ func TestFilterExpression(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestDefaultFilterTree(t *testing.T) {
+
+	id := filter.NewFieldExpression("id", filter.NewExpression(filter.Eq, "USR1"))
+	name := filter.NewFieldExpression("name", filter.NewExpression(filter.Contains, "John"))
+	status := filter.NewFieldExpression("status", filter.NewExpression(filter.Eq, "active"))
+
+	or := filter.New().
+		AddField(id).
+		AddField(name)
+	active := filter.New().AddField(status)
+
+	root := filter.New()
+	if root.And(or).And(active) != root {
+		t.Fatal("builder must return the same instance")
+	}
+	root.Not(filter.New().AddField(name), nil)
+
+	if len(root.GetAnd()) != 2 || root.GetAnd()[0] != or || root.GetAnd()[1] != active {
+		t.Fatalf("unexpected and filters %v", root.GetAnd())
+	}
+	if len(root.GetNot()) != 1 {
+		t.Fatalf("nil filter must be skipped, got %d", len(root.GetNot()))
+	}
+	if root.GetOr() == nil || len(root.GetOr()) != 0 {
+		t.Fatal("missing or filters must be an empty slice")
+	}
+
+	fields := or.GetFields()
+	if len(fields) != 2 || fields[0].Name() != "id" || fields[1].Name() != "name" {
+		t.Fatalf("unexpected fields order %v", fields)
+	}
+	fields[0] = status
+	if or.GetFields()[0].Name() != "id" {
+		t.Fatal("getter must return a copy")
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"testing"
+	"time"
+)
+
+var customerSchema = filter.Schema{
+	"id":      {Type: filter.StringType, Operations: []filter.Operation{filter.Eq, filter.In}},
+	"name":    {Type: filter.StringType},
+	"age":     {Type: filter.IntType},
+	"score":   {Type: filter.FloatType},
+	"active":  {Type: filter.BoolType},
+	"created": {Type: filter.TimeType},
+	"tags":    {Type: filter.StringType, List: true},
+}
+
+func TestFilterValidateValid(t *testing.T) {
+	now := time.Now()
+	f := filter.New().
+		AddField(field("id", filter.In, []string{"USR1", "USR2"})).
+		AddField(field("name", filter.RegEx, "^J")).
+		AddField(field("age", filter.Between, []int{18, 30})).
+		AddField(field("score", filter.Gt, 3)).
+		AddField(field("active", filter.Eq, true)).
+		AddField(field("created", filter.Lte, now)).
+		AddField(field("tags", filter.Contains, "vip")).
+		Or(filter.New().AddField(field("name", filter.IsNull, nil))).
+		Not(filter.New().AddField(field("name", filter.Eq, nil)))
+
+	if err := filter.Validate(f, customerSchema); err != nil {
+		t.Fatal(err)
+	}
+	if err := filter.Validate(nil, customerSchema); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFilterValidateViolations(t *testing.T) {
+	f := filter.New().
+		AddField(field("password", filter.Eq, "x")).
+		AddField(field("id", filter.StartWith, "USR")).
+		And(filter.New().
+			AddField(field("age", filter.Between, []int{30, 18})).
+			AddField(field("age", filter.Between, []int{1})).
+			AddField(field("active", filter.Gt, false))).
+		Or(
+			filter.New().AddField(field("name", filter.RegEx, "(")),
+			filter.New().AddField(field("age", filter.Eq, "thirty")),
+		).
+		Not(filter.New().
+			AddField(field("tags", filter.StartWith, "v")).
+			AddField(field("name", filter.IsNull, "x")).
+			AddField(field("age", filter.In, []any{1, "2"})).
+			AddField(field("score", filter.Lt, nil)))
+
+	err := filter.Validate(f, customerSchema)
+	var validationErr *filter.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	expected := []struct {
+		path  string
+		field string
+	}{
+		{"filter.fields[0]", "password"},
+		{"filter.fields[1]", "id"},
+		{"filter.and[0].fields[0]", "age"},
+		{"filter.and[0].fields[1]", "age"},
+		{"filter.and[0].fields[2]", "active"},
+		{"filter.or[0].fields[0]", "name"},
+		{"filter.or[1].fields[0]", "age"},
+		{"filter.not[0].fields[0]", "tags"},
+		{"filter.not[0].fields[1]", "name"},
+		{"filter.not[0].fields[2]", "age"},
+		{"filter.not[0].fields[3]", "score"},
+	}
+	if len(validationErr.Violations) != len(expected) {
+		t.Fatalf("expected %d violations, got %v", len(expected), err)
+	}
+	for i, e := range expected {
+		v := validationErr.Violations[i]
+		if v.Path != e.path || v.Field != e.field || v.Message == "" {
+			t.Errorf("unexpected violation %v, expected %s (%s)", v, e.path, e.field)
+		}
+	}
+}
+
+func TestFilterValidateCustomOperation(t *testing.T) {
+	geo := registerGeoWithin(t)
+	schema := filter.Schema{
+		"location": {Type: filter.FloatType, Operations: []filter.Operation{geo}},
+		"name":     {Type: filter.StringType},
+	}
+
+	if err := filter.Validate(filter.New().AddField(field("location", geo, []float64{1, 2})), schema); err != nil {
+		t.Fatal(err)
+	}
+	if err := filter.Validate(filter.New().AddField(field("location", geo, 1.0)), schema); err == nil {
+		t.Fatal("expected error for non-list value of variadic operation")
+	}
+	if err := filter.Validate(filter.New().AddField(field("name", geo, []float64{1})), schema); err == nil {
+		t.Fatal("custom operation must be listed explicitly")
+	}
+}
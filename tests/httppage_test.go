@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/httppage"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+var nextLink = regexp.MustCompile(`<([^>]+)>; rel="next"`)
+
+func newAccountHandler(t *testing.T) http.Handler {
+	d := newFilledAccountDAO(t, 5)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err, pagination := httppage.Parse(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err, response := d.Read(r.Context(), &dao.ReadRequest[AccountFilter]{Pagination: pagination})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		httppage.WriteHeaders(w, r, response.Pagination)
+		_ = json.NewEncoder(w).Encode(response.Data)
+	})
+}
+
+func TestHTTPPaginationFollowsLinks(t *testing.T) {
+	handler := newAccountHandler(t)
+
+	target := "/accounts?status=active&page_size=2"
+	count := 0
+	for pages := 0; target != ""; pages++ {
+		if pages > 5 {
+			t.Fatal("too many pages")
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d: %s", recorder.Code, recorder.Body)
+		}
+		if recorder.Header().Get(httppage.TotalCountHeader) != "5" {
+			t.Fatalf("unexpected total %q", recorder.Header().Get(httppage.TotalCountHeader))
+		}
+
+		var accounts []Account
+		if err := json.NewDecoder(recorder.Body).Decode(&accounts); err != nil {
+			t.Fatal(err)
+		}
+		count += len(accounts)
+
+		target = ""
+		if m := nextLink.FindStringSubmatch(recorder.Header().Get("Link")); m != nil {
+			target = m[1]
+			if recorder.Header().Get(httppage.HasNextHeader) != "true" {
+				t.Fatal("next link requires X-Has-Next: true")
+			}
+			if !regexp.MustCompile(`^/accounts\?page_size=2&page_token=[A-Za-z0-9_-]+&status=active$`).MatchString(target) {
+				t.Fatalf("unexpected next link %s", target)
+			}
+		}
+	}
+	if count != 5 {
+		t.Fatalf("expected 5 accounts, got %d", count)
+	}
+}
+
+func TestHTTPPaginationParse(t *testing.T) {
+	err, p := httppage.Parse(httptest.NewRequest(http.MethodGet, "/?page_size=10&prev_page_token=cHJldg", nil))
+	if err != nil || *p.Size != 10 || string(*p.PrevToken) != "prev" || p.NextToken != nil {
+		t.Fatalf("unexpected pagination %v %+v", err, p)
+	}
+
+	for _, target := range []string{"/?page_size=-1", "/?page_size=x", "/?page_token=%21%21"} {
+		if err, _ := httppage.Parse(httptest.NewRequest(http.MethodGet, target, nil)); !errors.Is(err, dao.ErrInvalidPagination) {
+			t.Fatalf("%s: expected ErrInvalidPagination, got %v", target, err)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/items?page_token=abc", nil)
+	prev := []byte("prev")
+	httppage.WriteHeaders(recorder, request, &dao.Pagination{PrevToken: &prev, HasNext: ptr(false)})
+	if link := recorder.Header().Get("Link"); link != `</items?prev_page_token=cHJldg>; rel="prev"` {
+		t.Fatalf("unexpected link %s", link)
+	}
+	if recorder.Header().Get(httppage.HasNextHeader) != "false" || recorder.Header().Get(httppage.TotalCountHeader) != "" {
+		t.Fatal("unexpected headers")
+	}
+}
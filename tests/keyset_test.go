@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/dao/cursor"
+	"github.com/hard-simple/go-dao/pkg/contract/dao/keyset"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"sort"
+	"testing"
+)
+
+type Player struct {
+	ID    string `dao:"id"`
+	Score int    `dao:"score"`
+	Team  string `dao:"team"`
+}
+
+var players = []Player{
+	{"p1", 10, "red"}, {"p2", 30, "red"}, {"p3", 20, "blue"}, {"p4", 30, "red"}, {"p5", 20, "red"},
+	{"p6", 10, "red"}, {"p7", 40, "red"}, {"p8", 20, "red"}, {"p9", 5, "blue"},
+}
+
+func newPlayerPaginator(t *testing.T) *keyset.Paginator[Player] {
+	_, codec := cursor.NewCodec(cursorKey, 0)
+	err, p := keyset.New(codec, func(p Player) []any { return []any{p.Score, p.ID} },
+		keyset.SortKey{Field: "score", Descending: true}, keyset.SortKey{Field: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// readPlayers emulates a backend which applies the keyset filter and sort order.
+func readPlayers(t *testing.T, p *keyset.Paginator[Player], base filter.Filter, pagination *dao.Pagination) ([]Player, *dao.Pagination) {
+	err, f, backward := p.Filter(base, pagination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := p.Sort(backward)
+	sorted := append([]Player(nil), players...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Score != b.Score {
+			return (a.Score < b.Score) != keys[0].Descending
+		}
+		return (a.ID < b.ID) != keys[1].Descending
+	})
+
+	fetched := make([]Player, 0)
+	for _, player := range sorted {
+		if f != nil {
+			if err, ok := filter.Match(f, player); err != nil {
+				t.Fatal(err)
+			} else if !ok {
+				continue
+			}
+		}
+		if uint(len(fetched)) == *pagination.Size+1 {
+			break
+		}
+		fetched = append(fetched, player)
+	}
+	err, page, response := p.Page(fetched, *pagination.Size, pagination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page, response
+}
+
+func ids(items []Player) string {
+	result := ""
+	for _, item := range items {
+		result += item.ID + " "
+	}
+	return result
+}
+
+func TestKeysetPaginationBothDirections(t *testing.T) {
+	p := newPlayerPaginator(t)
+	base := filter.New().AddField(field("team", filter.Eq, "red"))
+
+	page, response := readPlayers(t, p, base, &dao.Pagination{Size: ptr(uint(3))})
+	if ids(page) != "p7 p2 p4 " || !*response.HasNext || response.PrevToken != nil {
+		t.Fatalf("unexpected first page %v %+v", ids(page), response)
+	}
+
+	page, response = readPlayers(t, p, base, &dao.Pagination{Size: ptr(uint(3)), NextToken: response.NextToken})
+	if ids(page) != "p5 p8 p1 " || !*response.HasNext || response.PrevToken == nil {
+		t.Fatalf("unexpected second page %v", ids(page))
+	}
+	prev := response.PrevToken
+
+	page, response = readPlayers(t, p, base, &dao.Pagination{Size: ptr(uint(3)), NextToken: response.NextToken})
+	if ids(page) != "p6 " || *response.HasNext || response.NextToken != nil {
+		t.Fatalf("unexpected last page %v", ids(page))
+	}
+
+	page, response = readPlayers(t, p, base, &dao.Pagination{Size: ptr(uint(3)), PrevToken: prev})
+	if ids(page) != "p7 p2 p4 " || !*response.HasNext || response.PrevToken != nil {
+		t.Fatalf("unexpected previous page %v %+v", ids(page), response)
+	}
+
+	page, _ = readPlayers(t, p, base, &dao.Pagination{Size: ptr(uint(2)), NextToken: response.NextToken})
+	if ids(page) != "p5 p8 " {
+		t.Fatalf("the token must keep its direction, got %v", ids(page))
+	}
+}
+
+func TestKeysetPaginationIsStableUnderInserts(t *testing.T) {
+	p := newPlayerPaginator(t)
+	page, response := readPlayers(t, p, nil, &dao.Pagination{Size: ptr(uint(4))})
+	if ids(page) != "p7 p2 p4 p3 " {
+		t.Fatalf("unexpected first page %v", ids(page))
+	}
+
+	saved := players
+	defer func() { players = saved }()
+	players = append(append([]Player(nil), saved...), Player{"p0", 50, "red"})
+
+	page, _ = readPlayers(t, p, nil, &dao.Pagination{Size: ptr(uint(4)), NextToken: response.NextToken})
+	if ids(page) != "p5 p8 p1 p6 " {
+		t.Fatalf("the inserted row must not shift the page, got %v", ids(page))
+	}
+}
+
+func TestKeysetRejectsForeignTokens(t *testing.T) {
+	p := newPlayerPaginator(t)
+	_, codec := cursor.NewCodec(cursorKey, 0)
+	_, token := codec.Encode(cursor.Cursor{Keys: []any{1}})
+	if err, _, _ := p.Filter(nil, &dao.Pagination{NextToken: &token}); err == nil {
+		t.Fatal("expected error for token with wrong number of keys")
+	}
+	if err, _ := keyset.New[Player](codec, nil); err == nil {
+		t.Fatal("expected error for missing key function")
+	}
+}
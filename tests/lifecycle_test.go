@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/factory"
+	"github.com/hard-simple/go-dao/pkg/contract/lifecycle"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closeRecorder records the order of Close calls.
+type closeRecorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *closeRecorder) closer(name string, delay time.Duration, err error) *recordingCloser {
+	return &recordingCloser{recorder: r, name: name, delay: delay, err: err}
+}
+
+type recordingCloser struct {
+	recorder *closeRecorder
+	name     string
+	delay    time.Duration
+	err      error
+}
+
+func (c *recordingCloser) Close() error {
+	time.Sleep(c.delay)
+	c.recorder.mu.Lock()
+	defer c.recorder.mu.Unlock()
+	c.recorder.order = append(c.recorder.order, c.name)
+	return c.err
+}
+
+func TestLifecycleShutdownOrderAndErrors(t *testing.T) {
+	recorder := &closeRecorder{}
+	m := lifecycle.New()
+	first := recorder.closer("first", 0, nil)
+	_ = m.Add("first", first)
+	_ = m.Add("first", first)
+	_ = m.Add("second", recorder.closer("second", 0, errors.New("connection reset")))
+	_ = m.Add("third", recorder.closer("third-a", 20*time.Millisecond, nil))
+	_ = m.Add("third", recorder.closer("third-b", 20*time.Millisecond, nil))
+	if m.Len() != 4 {
+		t.Fatalf("expected 4 closers, got %d", m.Len())
+	}
+
+	started := time.Now()
+	err := m.Shutdown(context.Background())
+	if err == nil || err.Error() != "second: connection reset" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 35*time.Millisecond {
+		t.Fatalf("closers of the same name must be closed in parallel, took %v", elapsed)
+	}
+	order := recorder.order
+	if len(order) != 4 || order[2] != "second" || order[3] != "first" {
+		t.Fatalf("unexpected close order %v", order)
+	}
+
+	if err := m.Add("late", recorder.closer("late", 0, nil)); !errors.Is(err, lifecycle.ErrShutdown) {
+		t.Fatalf("expected ErrShutdown, got %v", err)
+	}
+	if err := m.Shutdown(context.Background()); err != nil || len(recorder.order) != 4 {
+		t.Fatal("closed closers must not be closed again")
+	}
+}
+
+func TestLifecycleShutdownHonorsDeadline(t *testing.T) {
+	recorder := &closeRecorder{}
+	m := lifecycle.New()
+	_ = m.Add("fast", recorder.closer("fast", 0, nil))
+	_ = m.Add("slow", recorder.closer("slow", 200*time.Millisecond, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := m.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if m.Len() != 2 {
+		t.Fatal("closers must stay recorded when they aren't closed")
+	}
+}
+
+func TestLifecycleTracksFactoryAndSignals(t *testing.T) {
+	ctx, _ := newTrackedScope(t, "accounts")
+	m := lifecycle.New()
+	f := lifecycle.Track(m, factory.NewPrototypeDAOFactory[string, Account, AccountFilter](ctx))
+
+	made := make([]*dao.DAO[string, Account, AccountFilter], 0)
+	for i := 0; i < 3; i++ {
+		err, d := f.Make(ctx, "accounts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		made = append(made, d)
+	}
+	if m.Len() != 3 {
+		t.Fatalf("expected 3 tracked daos, got %d", m.Len())
+	}
+
+	done := m.ShutdownOnSignal(context.Background(), time.Second, os.Interrupt)
+	process, _ := os.FindProcess(os.Getpid())
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Skip("signals aren't supported: ", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown hasn't been triggered by the signal")
+	}
+	for _, d := range made {
+		if !tracked(d).closed.Load() {
+			t.Fatal("every made dao must be closed")
+		}
+	}
+	if err, _ := f.Make(ctx, "accounts"); !errors.Is(err, lifecycle.ErrShutdown) {
+		t.Fatalf("expected ErrShutdown, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	result := lifecycle.New().ShutdownOnSignal(cancelled, 0)
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLifecycleShutdownFollowsCreationOrder(t *testing.T) {
+	recorder := &closeRecorder{}
+	m := lifecycle.New()
+	_ = m.Add("accounts", recorder.closer("accounts-1", 0, nil))
+	_ = m.Add("orders", recorder.closer("orders", 0, nil))
+	_ = m.Add("accounts", recorder.closer("accounts-2", 0, nil))
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if order := recorder.order; len(order) != 3 || order[0] != "accounts-2" || order[1] != "orders" || order[2] != "accounts-1" {
+		t.Fatalf("closers must be closed in reverse creation order, got %v", order)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("closed closers must be forgotten, got %d", m.Len())
+	}
+}
+
+func TestLifecycleShutdownAfterDeadlineDoesNotCloseTwice(t *testing.T) {
+	recorder := &closeRecorder{}
+	m := lifecycle.New()
+	_ = m.Add("fast", recorder.closer("fast", 0, nil))
+	_ = m.Add("slow", recorder.closer("slow", 100*time.Millisecond, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if order := recorder.order; len(order) != 2 || order[0] != "fast" || order[1] != "slow" {
+		t.Fatalf("every closer must be closed once, got %v", order)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("closed closers must be forgotten, got %d", m.Len())
+	}
+}
+
+// valueCloser is a comparable closer type which could hold an uncomparable value.
+type valueCloser struct {
+	value  any
+	closed *atomic.Int32
+}
+
+func (c valueCloser) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+func TestLifecycleRecordsSameCloserOnce(t *testing.T) {
+	recorder := &closeRecorder{}
+	m := lifecycle.New()
+	shared := recorder.closer("shared", 0, nil)
+	_ = m.Add("a", shared)
+	_ = m.Add("b", shared)
+
+	closed := &atomic.Int32{}
+	_ = m.Add("slice", valueCloser{value: []int{1}, closed: closed})
+	_ = m.Add("slice", valueCloser{value: []int{1}, closed: closed})
+	if m.Len() != 3 {
+		t.Fatalf("expected 3 closers, got %d", m.Len())
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.order) != 1 || closed.Load() != 2 {
+		t.Fatalf("every closer must be closed once, got %v and %d", recorder.order, closed.Load())
+	}
+}
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/memory"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type Account struct {
+	ID     string
+	Name   string
+	Status string
+	Age    int
+}
+
+type AccountFilter struct {
+	NamePrefix string
+}
+
+func newAccountDAO() dao.DAO[string, Account, AccountFilter] {
+	return memory.New[string, Account, AccountFilter](
+		func(a Account) string { return a.ID },
+		func(f *AccountFilter, a Account) (error, bool) {
+			return nil, strings.HasPrefix(a.Name, f.NamePrefix)
+		},
+	)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestMemoryCreateAndUpsert(t *testing.T) {
+	ctx := context.Background()
+	d := newAccountDAO()
+
+	err, resp := d.Create(ctx, &dao.CreateRequest[Account]{Data: Account{ID: "1", Name: "John"}})
+	if err != nil || !*resp.Created || *resp.Updated {
+		t.Fatalf("unexpected create result: %v %+v", err, resp)
+	}
+
+	err, _ = d.Create(ctx, &dao.CreateRequest[Account]{Data: Account{ID: "1", Name: "Jane"}})
+	if !errors.Is(err, memory.ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	err, resp = d.Create(ctx, &dao.CreateRequest[Account]{Data: Account{ID: "1", Name: "Jane"}, Upsert: ptr(true)})
+	if err != nil || *resp.Created || !*resp.Updated {
+		t.Fatalf("unexpected upsert result: %v %+v", err, resp)
+	}
+
+	err, read := d.Read(ctx, &dao.ReadRequest[AccountFilter]{})
+	if err != nil || len(read.Data) != 1 || read.Data[0].Name != "Jane" {
+		t.Fatalf("unexpected read result: %v %+v", err, read)
+	}
+}
+
+func TestMemoryBulkCreatePartial(t *testing.T) {
+	ctx := context.Background()
+	d := newAccountDAO()
+
+	_, _ = d.Create(ctx, &dao.CreateRequest[Account]{Data: Account{ID: "2"}})
+
+	batch := []Account{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	err, _ := d.BulkCreate(ctx, &dao.BulkCreateRequest[Account]{Data: batch})
+	if !errors.Is(err, memory.ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+	_, read := d.Read(ctx, &dao.ReadRequest[AccountFilter]{})
+	if len(read.Data) != 1 {
+		t.Fatalf("non-partial bulk create must not store anything, got %d items", len(read.Data))
+	}
+
+	err, resp := d.BulkCreate(ctx, &dao.BulkCreateRequest[Account]{Data: batch, Partial: ptr(true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*resp.Data) != 2 || resp.Metadata["errors"] == nil {
+		t.Fatalf("unexpected partial result: %+v", resp)
+	}
+}
+
+func TestMemoryReadPagination(t *testing.T) {
+	ctx := context.Background()
+	d := newAccountDAO()
+
+	names := []string{"Ann", "Bob", "Alex", "Amy", "Ben", "Abe"}
+	for i, name := range names {
+		_, _ = d.Create(ctx, &dao.CreateRequest[Account]{Data: Account{ID: string(rune('a' + i)), Name: name}})
+	}
+
+	var got []string
+	pagination := &dao.Pagination{Size: ptr[uint](2)}
+	for {
+		err, resp := d.Read(ctx, &dao.ReadRequest[AccountFilter]{
+			Filter:     &AccountFilter{NamePrefix: "A"},
+			Pagination: pagination,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range resp.Data {
+			got = append(got, a.Name)
+		}
+		if *resp.Pagination.Total != 4 {
+			t.Fatalf("expected total 4, got %d", *resp.Pagination.Total)
+		}
+		if !*resp.Pagination.HasNext {
+			if resp.Pagination.NextToken != nil {
+				t.Fatal("last page must not have next token")
+			}
+			break
+		}
+		pagination = &dao.Pagination{Size: ptr[uint](2), NextToken: resp.Pagination.NextToken}
+	}
+
+	if strings.Join(got, ",") != "Ann,Alex,Amy,Abe" {
+		t.Fatalf("unexpected order %v", got)
+	}
+
+	err, resp := d.RangeRead(ctx, &dao.RangeReadRequest[AccountFilter]{
+		Pagination: &dao.Pagination{Offset: ptr[uint](4), Size: ptr[uint](10)},
+	})
+	if err != nil || len(resp.Data) != 2 || *resp.Pagination.HasNext {
+		t.Fatalf("unexpected range read result: %v %+v", err, resp)
+	}
+}
+
+func TestMemoryUpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	d := newAccountDAO()
+
+	err, _ := d.Update(ctx, &dao.UpdateRequest[Account]{Data: Account{ID: "1"}})
+	if !errors.Is(err, memory.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	err, upd := d.Update(ctx, &dao.UpdateRequest[Account]{Data: Account{ID: "1"}, Upsert: ptr(true)})
+	if err != nil || !*upd.Created {
+		t.Fatalf("unexpected upsert result: %v %+v", err, upd)
+	}
+
+	err, bulk := d.BulkUpdate(ctx, &dao.BulkUpdateRequest[Account]{
+		Data:    []Account{{ID: "1", Status: "active"}, {ID: "2"}},
+		Partial: ptr(true),
+	})
+	if err != nil || len(*bulk.Data) != 1 {
+		t.Fatalf("unexpected bulk update result: %v %+v", err, bulk)
+	}
+
+	err, _ = d.BulkDelete(ctx, &dao.BulkDeleteRequest[string]{Keys: []string{"1", "2"}})
+	if !errors.Is(err, memory.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	err, del := d.BulkDelete(ctx, &dao.BulkDeleteRequest[string]{Keys: []string{"1", "2"}, Partial: ptr(true)})
+	if err != nil || len(*del.Deleted) != 1 || (*del.Deleted)[0] != "1" {
+		t.Fatalf("unexpected bulk delete result: %v %+v", err, del)
+	}
+
+	err, _ = d.Delete(ctx, &dao.DeleteRequest[string]{Key: "1"})
+	if !errors.Is(err, memory.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMemoryConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	d := newAccountDAO()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := string(rune('A' + i))
+			_, _ = d.Create(ctx, &dao.CreateRequest[Account]{Data: Account{ID: id}})
+			_, _ = d.Read(ctx, &dao.ReadRequest[AccountFilter]{})
+			_, _ = d.Delete(ctx, &dao.DeleteRequest[string]{Key: id})
+		}(i)
+	}
+	wg.Wait()
+
+	_, resp := d.Read(ctx, &dao.ReadRequest[AccountFilter]{})
+	if len(resp.Data) != 0 {
+		t.Fatalf("expected empty storage, got %d items", len(resp.Data))
+	}
+
+	_ = d.Close()
+	err, _ := d.Read(ctx, &dao.ReadRequest[AccountFilter]{})
+	if !errors.Is(err, memory.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"github.com/hard-simple/go-dao/pkg/contract/filter/mongofilter"
+	"reflect"
+	"testing"
+)
+
+func TestMongoFilterTranslate(t *testing.T) {
+	f := filter.New().
+		AddField(field("status", filter.Eq, "active")).
+		And(filter.New().Or(
+			filter.New().AddField(field("id", filter.Eq, "USR1")),
+			filter.New().AddField(field("name", filter.Contains, "J.")),
+		)).
+		Not(filter.New().AddField(field("age", filter.Between, []int{0, 17})))
+
+	err, doc := mongofilter.Translate(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := mongofilter.Document{"$and": []any{
+		mongofilter.Document{"status": mongofilter.Document{"$eq": "active"}},
+		mongofilter.Document{"$or": []any{
+			mongofilter.Document{"id": mongofilter.Document{"$eq": "USR1"}},
+			mongofilter.Document{"name": mongofilter.Document{"$regex": `J\.`}},
+		}},
+		mongofilter.Document{"$nor": []any{
+			mongofilter.Document{"age": mongofilter.Document{"$gte": 0, "$lte": 17}},
+		}},
+	}}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Fatalf("unexpected document:\n%v\n%v", doc, expected)
+	}
+}
+
+func TestMongoFilterOperators(t *testing.T) {
+	cases := []struct {
+		fe       filter.FieldExpression
+		expected mongofilter.Document
+	}{
+		{field("name", filter.StartWith, "a+"), mongofilter.Document{"name": mongofilter.Document{"$regex": `^a\+`}}},
+		{field("age", filter.Gt, 1), mongofilter.Document{"age": mongofilter.Document{"$gt": 1}}},
+		{field("age", filter.Gte, 1), mongofilter.Document{"age": mongofilter.Document{"$gte": 1}}},
+		{field("age", filter.Lt, 1), mongofilter.Document{"age": mongofilter.Document{"$lt": 1}}},
+		{field("age", filter.Lte, 1), mongofilter.Document{"age": mongofilter.Document{"$lte": 1}}},
+		{field("name", filter.RegEx, "^a+$"), mongofilter.Document{"name": mongofilter.Document{"$regex": "^a+$"}}},
+	}
+	for _, tc := range cases {
+		err, doc := mongofilter.Translate(filter.New().AddField(tc.fe))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(doc, tc.expected) {
+			t.Errorf("unexpected document %v, expected %v", doc, tc.expected)
+		}
+	}
+
+	if err, doc := mongofilter.Translate(filter.New()); err != nil || len(doc) != 0 {
+		t.Fatalf("empty filter must produce empty document, got %v %v", doc, err)
+	}
+	if err, _ := mongofilter.Translate(filter.New().AddField(field("age", filter.Between, 1))); err == nil {
+		t.Fatal("expected error for invalid range")
+	}
+	if err, _ := mongofilter.Translate(filter.New().AddField(field("age", filter.Undefined, 1))); err == nil {
+		t.Fatal("expected error for undefined operation")
+	}
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/memory"
+	"testing"
+)
+
+func TestPaginationPolicyNormalize(t *testing.T) {
+	policy := dao.PaginationPolicy{DefaultSize: 20, MaxSize: 50}
+
+	err, p := policy.Normalize(nil)
+	if err != nil || *p.Size != 20 || *p.Offset != 0 || p.NextToken != nil || p.PrevToken != nil {
+		t.Fatalf("unexpected normalized nil pagination %v %+v", err, p)
+	}
+
+	next, prev := []byte("next"), []byte("prev")
+	request := &dao.Pagination{
+		Size: ptr(uint(500)), Offset: ptr(uint(3)), NextToken: &next, PrevToken: &prev,
+		Total: ptr(uint64(1)), HasNext: ptr(true),
+	}
+	err, p = policy.Normalize(request)
+	if err != nil || *p.Size != 50 || string(*p.NextToken) != "next" || p.PrevToken != nil || p.Offset != nil {
+		t.Fatalf("NextToken must win over PrevToken and Offset, got %v %+v", err, p)
+	}
+	if p.Total != nil || p.HasNext != nil {
+		t.Fatal("response fields must be dropped")
+	}
+	if *request.Size != 500 || request.PrevToken == nil {
+		t.Fatal("request must not be changed")
+	}
+
+	err, p = policy.Normalize(&dao.Pagination{Offset: ptr(uint(3)), PrevToken: &prev})
+	if err != nil || string(*p.PrevToken) != "prev" || p.Offset != nil {
+		t.Fatalf("PrevToken must win over Offset, got %v %+v", err, p)
+	}
+
+	err, p = policy.Normalize(&dao.Pagination{Offset: ptr(uint(3))})
+	if err != nil || *p.Offset != 3 {
+		t.Fatalf("unexpected offset %v %+v", err, p)
+	}
+}
+
+func TestPaginationPolicyRejects(t *testing.T) {
+	policy := dao.PaginationPolicy{DefaultSize: 20, AllowOffset: ptr(false), RequireTotal: true}
+	empty := []byte{}
+
+	requests := []*dao.Pagination{
+		{Size: ptr(uint(0))},
+		{Offset: ptr(uint(10))},
+		{NextToken: &empty},
+	}
+	for i, request := range requests {
+		if err, _ := policy.Normalize(request); !errors.Is(err, dao.ErrInvalidPagination) {
+			t.Fatalf("request %d: expected ErrInvalidPagination, got %v", i, err)
+		}
+	}
+	if err, _ := (dao.PaginationPolicy{}).Normalize(nil); err == nil {
+		t.Fatal("expected error for policy without default size")
+	}
+
+	if err := policy.CheckResponse(&dao.Pagination{}); err == nil {
+		t.Fatal("expected error for response without total")
+	}
+	if err := policy.CheckResponse(&dao.Pagination{Total: ptr(uint64(0))}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryMaxPageSize(t *testing.T) {
+	d := newFilledAccountDAO(t, 10)
+	if err := d.Configure(context.Background(), memory.Config{MaxPageSize: 4}); err != nil {
+		t.Fatal(err)
+	}
+	err, response := d.Read(context.Background(), &dao.ReadRequest[AccountFilter]{
+		Pagination: &dao.Pagination{Size: ptr(uint(100))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Data) != 4 || *response.Pagination.Size != 4 {
+		t.Fatalf("expected page of 4, got %d", len(response.Data))
+	}
+}
+
+func TestMemoryNilPaginationFollowsPolicy(t *testing.T) {
+	d := newFilledAccountDAO(t, 10)
+	if err := d.Configure(context.Background(), memory.Config{DefaultPageSize: 6, MaxPageSize: 4}); err != nil {
+		t.Fatal(err)
+	}
+	err, response := d.Read(context.Background(), &dao.ReadRequest[AccountFilter]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Data) != 4 || !*response.Pagination.HasNext {
+		t.Fatalf("request without pagination must be limited, got %d", len(response.Data))
+	}
+}
+
+func TestMemoryTotalModes(t *testing.T) {
+	d := newFilledAccountDAO(t, 100)
+	read := func(mode dao.TotalMode) *dao.Pagination {
+		err, response := d.Read(context.Background(), &dao.ReadRequest[AccountFilter]{
+			Filter:     &AccountFilter{NamePrefix: "user0"},
+			Pagination: &dao.Pagination{Size: ptr(uint(3)), TotalMode: &mode},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Data) != 3 || !*response.Pagination.HasNext {
+			t.Fatalf("unexpected page for %s mode", mode)
+		}
+		return response.Pagination
+	}
+
+	exact := read(dao.TotalExact)
+	if *exact.Total != 10 || *exact.TotalKind != dao.TotalExact {
+		t.Fatalf("unexpected exact total %d %s", *exact.Total, *exact.TotalKind)
+	}
+
+	estimated := read(dao.TotalEstimated)
+	if *estimated.TotalKind != dao.TotalEstimated || *estimated.Total != 100 {
+		t.Fatalf("unexpected estimated total %d %s", *estimated.Total, *estimated.TotalKind)
+	}
+
+	skipped := read(dao.TotalSkipped)
+	if skipped.Total != nil || skipped.TotalKind != nil {
+		t.Fatal("skipped total must not be reported")
+	}
+
+	if err, _ := (dao.PaginationPolicy{DefaultSize: 1, RequireTotal: true}).Normalize(&dao.Pagination{TotalMode: &dao.TotalSkipped}); !errors.Is(err, dao.ErrInvalidPagination) {
+		t.Fatalf("skipped total must break the policy which requires it, got %v", err)
+	}
+}
+
+func TestMemoryHugePageSizeDoesNotOverflow(t *testing.T) {
+	d := newFilledAccountDAO(t, 5)
+	token := []byte("2")
+	for _, mode := range []dao.TotalMode{dao.TotalExact, dao.TotalEstimated, dao.TotalSkipped} {
+		for _, p := range []*dao.Pagination{
+			{Size: ptr(^uint(0)), NextToken: &token, TotalMode: &mode},
+			{Size: ptr(^uint(0)), PrevToken: &token, TotalMode: &mode},
+			{Size: ptr(^uint(0)), Offset: ptr(^uint(0)), TotalMode: &mode},
+		} {
+			err, response := d.Read(context.Background(), &dao.ReadRequest[AccountFilter]{Pagination: p})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *response.Pagination.HasNext {
+				t.Fatalf("%s mode: unexpected next page", mode)
+			}
+			if p.NextToken != nil && len(response.Data) != 3 {
+				t.Fatalf("%s mode: expected 3 accounts after the token, got %d", mode, len(response.Data))
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/dao/prefetch"
+	"github.com/hard-simple/go-dao/pkg/memory"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowAccountDAO delays every Read and fails it after the configured number of calls.
+type slowAccountDAO struct {
+	dao.DAO[string, Account, AccountFilter]
+	delay   time.Duration
+	failAt  int32
+	calls   atomic.Int32
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (s *slowAccountDAO) Read(ctx context.Context, request *dao.ReadRequest[AccountFilter]) (error, *dao.ReadResponse[Account]) {
+	running := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		peak := s.peak.Load()
+		if running <= peak || s.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+
+	if call := s.calls.Add(1); s.failAt > 0 && call >= s.failAt {
+		return errors.New("backend is unavailable"), nil
+	}
+	time.Sleep(s.delay)
+	return s.DAO.Read(ctx, request)
+}
+
+func collectAccounts(t *testing.T, reader *prefetch.Reader[Account]) []string {
+	ids := make([]string, 0)
+	for account, err := range reader.All(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, account.ID)
+	}
+	return ids
+}
+
+func TestPrefetchReadsAllPagesInOrder(t *testing.T) {
+	for _, concurrency := range []uint{1, 4} {
+		t.Run(fmt.Sprint(concurrency), func(t *testing.T) {
+			d := &slowAccountDAO{DAO: newFilledAccountDAO(t, 50), delay: time.Millisecond}
+			request := &dao.ReadRequest[AccountFilter]{Pagination: &dao.Pagination{Size: ptr(uint(7))}}
+			err, reader := prefetch.New(d, request, prefetch.Config{Depth: 3, Concurrency: concurrency})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := collectAccounts(t, reader)
+			if len(ids) != 50 {
+				t.Fatalf("expected 50 accounts, got %d", len(ids))
+			}
+			for i, id := range ids {
+				if id != fmt.Sprintf("A%02d", i) {
+					t.Fatalf("unexpected order at %d: %s", i, id)
+				}
+			}
+
+			stats := reader.Stats()
+			if stats.Pages != 8 || stats.Items != 50 || stats.Fetch <= 0 || stats.ItemsPerSecond() <= 0 {
+				t.Fatalf("unexpected stats %+v", stats)
+			}
+			if peak := d.peak.Load(); peak > int32(concurrency) || (concurrency > 1 && peak < 2) {
+				t.Fatalf("unexpected number of concurrent requests %d", peak)
+			}
+		})
+	}
+}
+
+func TestPrefetchPropagatesErrors(t *testing.T) {
+	d := &slowAccountDAO{DAO: newFilledAccountDAO(t, 50), failAt: 3}
+	request := &dao.ReadRequest[AccountFilter]{Pagination: &dao.Pagination{Size: ptr(uint(5))}}
+	_, reader := prefetch.New(d, request, prefetch.Config{})
+
+	count := 0
+	var lastErr error
+	for _, err := range reader.All(context.Background()) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		count++
+	}
+	if lastErr == nil || count != 10 {
+		t.Fatalf("expected error after 10 accounts, got %v after %d", lastErr, count)
+	}
+
+	if err, _ := prefetch.New(d, &dao.ReadRequest[AccountFilter]{}, prefetch.Config{Concurrency: 2}); err == nil {
+		t.Fatal("expected error for concurrent reader without page size")
+	}
+}
+
+func TestPrefetchStopsOnCancelAndBreak(t *testing.T) {
+	d := &slowAccountDAO{DAO: newFilledAccountDAO(t, 50), delay: time.Millisecond}
+	request := &dao.ReadRequest[AccountFilter]{Pagination: &dao.Pagination{Size: ptr(uint(5))}}
+	_, reader := prefetch.New(d, request, prefetch.Config{Depth: 2, Concurrency: 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var lastErr error
+	for account, err := range reader.All(ctx) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		if account.ID == "A12" {
+			cancel()
+		}
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", lastErr)
+	}
+
+	for range reader.Pages(context.Background()) {
+		break
+	}
+	if calls := d.calls.Load(); calls >= 20 {
+		t.Fatalf("fetching must stop with the iteration, got %d calls", calls)
+	}
+}
+
+func TestPrefetchConcurrentFollowsClampedPageSize(t *testing.T) {
+	d := newFilledAccountDAO(t, 20)
+	if err := d.Configure(context.Background(), memory.Config{MaxPageSize: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	request := &dao.ReadRequest[AccountFilter]{Pagination: &dao.Pagination{Size: ptr(uint(5))}}
+	err, reader := prefetch.New(d, request, prefetch.Config{Concurrency: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := collectAccounts(t, reader)
+	if len(ids) != 20 {
+		t.Fatalf("expected 20 accounts, got %d", len(ids))
+	}
+	for i, id := range ids {
+		if id != fmt.Sprintf("A%02d", i) {
+			t.Fatalf("unexpected order at %d: %s", i, id)
+		}
+	}
+}
+
+func TestPrefetchKeepsTotalMode(t *testing.T) {
+	for _, concurrency := range []uint{1, 3} {
+		t.Run(fmt.Sprint(concurrency), func(t *testing.T) {
+			d := newFilledAccountDAO(t, 10)
+			request := &dao.ReadRequest[AccountFilter]{
+				Pagination: &dao.Pagination{Size: ptr(uint(3)), TotalMode: &dao.TotalSkipped},
+			}
+			err, reader := prefetch.New(d, request, prefetch.Config{Concurrency: concurrency})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for response, err := range reader.Pages(context.Background()) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p := response.Pagination; p.Total != nil || p.TotalKind != nil {
+					t.Fatalf("page must skip total, got %s", *p.TotalKind)
+				}
+			}
+			if stats := reader.Stats(); stats.Pages != 4 {
+				t.Fatalf("expected 4 pages, got %d", stats.Pages)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"errors"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"github.com/hard-simple/go-dao/pkg/contract/filter/query"
+	"reflect"
+	"testing"
+)
+
+func TestQueryParse(t *testing.T) {
+	text := `status = "active" AND (name ~ "John" OR id = "USR1") AND NOT age < 18`
+
+	err, f := query.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := f.GetFields()
+	if len(fields) != 1 || fields[0].Name() != "status" || fields[0].Expression().Op() != filter.Eq {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	or := f.GetAnd()[0].GetOr()
+	if len(or) != 2 || or[0].GetFields()[0].Expression().Op() != filter.Contains || or[1].GetFields()[0].Name() != "id" {
+		t.Fatalf("unexpected or filters %v", or)
+	}
+	age := f.GetNot()[0].GetFields()[0]
+	if age.Name() != "age" || age.Expression().Op() != filter.Lt || age.Expression().Value() != 18 {
+		t.Fatalf("unexpected not filter %v", age)
+	}
+
+	if query.Format(f) != text {
+		t.Fatalf("unexpected format %s", query.Format(f))
+	}
+
+	err, ok := filter.Match(f, Customer{ID: "USR1", Status: "active", Age: 20})
+	if err != nil || !ok {
+		t.Fatalf("expected match, got %v %v", ok, err)
+	}
+}
+
+func TestQueryValuesAndOperators(t *testing.T) {
+	err, f := query.Parse("score >= -1.5 and name ^= \"J\\\"o\" and `order` =~ \"^a\" and tags = [\"a\", 2, true, null]\n" +
+		"and age between 18 and 30 and flag <= false and n > 1e3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name  string
+		op    filter.Operation
+		value any
+	}{
+		{"score", filter.Gte, -1.5},
+		{"name", filter.StartWith, `J"o`},
+		{"order", filter.RegEx, "^a"},
+		{"tags", filter.Eq, []any{"a", 2, true, nil}},
+		{"age", filter.Between, []any{18, 30}},
+		{"flag", filter.Lte, false},
+		{"n", filter.Gt, 1000.0},
+	}
+	fields := f.GetFields()
+	if len(fields) != len(expected) {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	for i, e := range expected {
+		fe := fields[i]
+		if fe.Name() != e.name || fe.Expression().Op() != e.op || !reflect.DeepEqual(fe.Expression().Value(), e.value) {
+			t.Errorf("unexpected field %s %v %#v", fe.Name(), fe.Expression().Op(), fe.Expression().Value())
+		}
+	}
+
+	formatted := query.Format(f)
+	err, again := query.Parse(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Format(again) != formatted {
+		t.Fatalf("format isn't stable:\n%s\n%s", query.Format(again), formatted)
+	}
+}
+
+func TestQueryFormatTree(t *testing.T) {
+	f := filter.New().
+		Or(
+			filter.New().AddField(field("a", filter.Eq, 1)).AddField(field("b", filter.Eq, 2.0)),
+			filter.New().AddField(field("c", filter.Eq, "x")),
+		).
+		Not(filter.New(), filter.New().AddField(field("d", filter.Gt, uint8(3))))
+
+	formatted := query.Format(f)
+	if formatted != `((a = 1 AND b = 2.0) OR c = "x") AND NOT () AND NOT d > 3` {
+		t.Fatalf("unexpected format %s", formatted)
+	}
+
+	err, parsed := query.Parse(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Format(parsed) != formatted {
+		t.Fatalf("unexpected round trip %s", query.Format(parsed))
+	}
+	if err, f := query.Parse("  "); err != nil || query.Format(f) != "" {
+		t.Fatal("empty query must produce empty filter")
+	}
+}
+
+func TestQuerySyntaxErrors(t *testing.T) {
+	cases := []struct {
+		text   string
+		line   int
+		column int
+	}{
+		{`status = `, 1, 10},
+		{`status "active"`, 1, 8},
+		{"a = 1 AND\n  (b = 2", 2, 9},
+		{`a = "open`, 1, 5},
+		{"a = 1\nOR b ! 2", 2, 6},
+		{`a between 1 or 2`, 1, 13},
+		{`a = 1 b = 2`, 1, 7},
+		{`a = "\q"`, 1, 6},
+	}
+	for _, tc := range cases {
+		err, _ := query.Parse(tc.text)
+		var syntaxErr *query.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("expected syntax error for %q, got %v", tc.text, err)
+			continue
+		}
+		if syntaxErr.Line != tc.line || syntaxErr.Column != tc.column {
+			t.Errorf("%q: expected %d:%d, got %v", tc.text, tc.line, tc.column, syntaxErr)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/factory"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"github.com/hard-simple/go-dao/pkg/memory"
+	"testing"
+)
+
+func TestScopesIsolateParallelTests(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+
+			scope := registry.NewScope(nil)
+			d := newAccountDAO()
+			if err := dao.RegisterScoped(scope, "in-memory", d); err != nil {
+				t.Fatal(err)
+			}
+			producer := config.Producer(func(ctx context.Context) config.Config {
+				return memory.Config{DefaultPageSize: uint(i + 1)}
+			})
+			if err := config.RegisterScoped(scope, "in-memory", producer); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := registry.WithScope(context.Background(), scope)
+			err, made := factory.NewSingletonDAOFactory[string, Account, AccountFilter](ctx).Make(ctx, "in-memory")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *made != d {
+				t.Fatal("factory must resolve the dao of the context scope")
+			}
+
+			_, _ = d.BulkCreate(ctx, &dao.BulkCreateRequest[Account]{Data: []Account{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}}})
+			_, response := d.Read(ctx, &dao.ReadRequest[AccountFilter]{Pagination: &dao.Pagination{}})
+			if len(response.Data) != i+1 {
+				t.Fatalf("expected config of scope %d, got page of %d", i, len(response.Data))
+			}
+		})
+	}
+}
+
+func TestScopeFallsBackToParent(t *testing.T) {
+	parent := registry.NewScope(nil)
+	child := registry.NewScope(parent)
+	_ = parent.DAOs().Register("shared", "parent")
+	_ = parent.DAOs().Register("overridden", "parent")
+	_ = child.DAOs().Register("overridden", "child")
+
+	ctx := registry.WithScope(context.Background(), child)
+	if err, v := dao.GetDAOFromContext[string](ctx, "shared"); err != nil || *v != "parent" {
+		t.Fatalf("expected parent instance, got %v %v", err, v)
+	}
+	if err, v := dao.GetDAOFromContext[string](ctx, "overridden"); err != nil || *v != "child" {
+		t.Fatalf("expected child instance, got %v %v", err, v)
+	}
+	if err, _ := dao.GetDAOFromContext[string](ctx, "missing"); err == nil {
+		t.Fatal("expected error for missing name")
+	}
+	if err, _ := config.GetConfigProducerFromContext(ctx, "shared"); err == nil {
+		t.Fatal("config producers must be resolved separately from daos")
+	}
+
+	if registry.ScopeFrom(context.Background()) != registry.Global() || child.Parent() != parent {
+		t.Fatal("context without scope must resolve the global scope")
+	}
+	name := "scope-global-fallback"
+	_ = dao.Register(name, "global")
+	t.Cleanup(func() { _ = dao.Unregister(name) })
+	withGlobal := registry.WithScope(context.Background(), registry.NewScope(registry.Global()))
+	if err, v := dao.GetDAOFromContext[string](withGlobal, name); err != nil || *v != "global" {
+		t.Fatalf("expected package-level instance, got %v %v", err, v)
+	}
+}
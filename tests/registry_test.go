@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"sync"
+	"testing"
+)
+
+func TestRegistryLifecycle(t *testing.T) {
+	r := registry.New[int](context.Background())
+	for i, name := range []string{"c", "a", "b"} {
+		if err := r.Register(name, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.Len() != 3 || fmt.Sprint(r.Names()) != "[a b c]" {
+		t.Fatalf("unexpected names %v", r.Names())
+	}
+
+	err, previous := r.Replace("a", 10)
+	if err != nil || *previous != 1 {
+		t.Fatalf("unexpected previous instance %v %v", err, previous)
+	}
+	if _, v := r.Get("a"); *v != 10 {
+		t.Fatalf("unexpected replaced instance %d", *v)
+	}
+	if err, _ := r.Replace("unknown", 1); err == nil {
+		t.Fatal("expected error for replacing unknown name")
+	}
+
+	if err := r.Unregister("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Unregister("a"); err == nil {
+		t.Fatal("expected error for unregistering unknown name")
+	}
+	if err := r.Register("a", 20); err != nil {
+		t.Fatalf("name must be free after Unregister: %v", err)
+	}
+
+	visited := ""
+	for name, instance := range r.All() {
+		visited += fmt.Sprintf("%s=%d ", name, instance)
+	}
+	if visited != "a=20 b=2 c=0 " {
+		t.Fatalf("unexpected iteration %q", visited)
+	}
+
+	visited = ""
+	r.Range(func(name string, instance int) bool {
+		_ = r.Unregister(name)
+		visited += name
+		return name != "b"
+	})
+	if visited != "ab" || fmt.Sprint(r.Names()) != "[c]" {
+		t.Fatalf("unexpected range %q, names %v", visited, r.Names())
+	}
+}
+
+func TestRegistryConcurrentAccess(t *testing.T) {
+	r := registry.New[int](context.Background())
+	_ = r.Register("shared", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("n%d", i)
+			for j := 0; j < 100; j++ {
+				_ = r.Register(name, j)
+				_, _ = r.Get("shared")
+				_, _ = r.Replace("shared", j)
+				_ = r.Names()
+				_ = r.Unregister(name)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if r.Len() != 1 {
+		t.Fatalf("unexpected registry size %d", r.Len())
+	}
+}
+
+func TestPackageRegistriesLifecycle(t *testing.T) {
+	name := "registry-lifecycle"
+	first, second := NewInMemoryUserDAO(), NewInMemoryUserDAO()
+	if err := dao.Register(name, first); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = dao.Unregister(name) })
+
+	err, previous := dao.Replace(name, second)
+	if err != nil || previous != first {
+		t.Fatalf("unexpected previous dao %v", err)
+	}
+	found := false
+	for registered, instance := range dao.Instances() {
+		found = found || (registered == name && instance == second)
+	}
+	if !found || dao.Len() < 1 {
+		t.Fatal("replaced dao must be listed")
+	}
+
+	producer := func(ctx context.Context) config.Config { return nil }
+	if err := config.Register(name, producer); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Unregister(name); err != nil {
+		t.Fatal(err)
+	}
+	for _, registered := range config.Names() {
+		if registered == name {
+			t.Fatal("unregistered producer must not be listed")
+		}
+	}
+}
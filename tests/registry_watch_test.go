@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"github.com/hard-simple/go-dao/pkg/contract/config"
+	"github.com/hard-simple/go-dao/pkg/contract/dao"
+	"github.com/hard-simple/go-dao/pkg/contract/registry"
+	"github.com/hard-simple/go-dao/pkg/memory"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistryWatchDeliversEventsInOrder(t *testing.T) {
+	r := registry.New[int](context.Background())
+	_ = r.Register("before", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := r.Watch(ctx)
+
+	_ = r.Register("a", 1)
+	for i := 2; i < 50; i++ {
+		_, _ = r.Replace("a", i)
+	}
+	_ = r.Unregister("a")
+
+	expected := []string{"registered a 1 0"}
+	for i := 2; i < 50; i++ {
+		expected = append(expected, fmt.Sprintf("replaced a %d %d", i, i-1))
+	}
+	expected = append(expected, "unregistered a 0 49")
+
+	for i, want := range expected {
+		select {
+		case event := <-events:
+			if got := fmt.Sprintf("%s %s %d %d", event.Kind, event.Name, event.Instance, event.Previous); got != want {
+				t.Fatalf("event %d: expected %q, got %q", i, want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d hasn't been delivered", i)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("unexpected event after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel must be closed after cancellation")
+	}
+}
+
+func TestRegistrySubscribeCallback(t *testing.T) {
+	r := registry.New[string](context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	received := make([]string, 0)
+	delivered := make(chan struct{}, 10)
+	r.Subscribe(ctx, func(event registry.Event[string]) {
+		// The callback could use the registry without blocking it.
+		_ = r.Names()
+		mu.Lock()
+		received = append(received, event.Kind.String()+":"+event.Instance)
+		mu.Unlock()
+		delivered <- struct{}{}
+	})
+
+	_ = r.Register("backend", "v1")
+	_, _ = r.Replace("backend", "v2")
+	for i := 0; i < 2; i++ {
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Fatal("event hasn't been delivered")
+		}
+	}
+	mu.Lock()
+	if fmt.Sprint(received) != "[registered:v1 replaced:v2]" {
+		t.Fatalf("unexpected events %v", received)
+	}
+	mu.Unlock()
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	_ = r.Unregister("backend")
+	select {
+	case <-delivered:
+		t.Fatal("events must not be delivered after cancellation")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestDAOWatchUnwrapsProviders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := dao.Watch[accountDAO](ctx)
+
+	name := "watched-accounts"
+	d := newAccountDAO()
+	_ = dao.RegisterProvider(name, func(ctx context.Context) (error, accountDAO) { return nil, d })
+	t.Cleanup(func() { _ = dao.Unregister(name) })
+	if err, _ := dao.GetDAO[accountDAO](name); err != nil {
+		t.Fatal(err)
+	}
+	replacement := newAccountDAO()
+	_, _ = dao.Replace(name, replacement)
+
+	expected := []registry.Event[accountDAO]{
+		{Kind: registry.Registered, Name: name},
+		{Kind: registry.Replaced, Name: name, Instance: replacement, Previous: d},
+	}
+	for i, want := range expected {
+		select {
+		case event := <-events:
+			if event != want {
+				t.Fatalf("event %d: expected %+v, got %+v", i, want, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d hasn't been delivered", i)
+		}
+	}
+}
+
+func TestConfigWatchDeliversProducers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	received := make([]string, 0)
+	delivered := make(chan struct{}, 3)
+	config.Subscribe(ctx, func(event registry.Event[config.Producer]) {
+		mu.Lock()
+		defer mu.Unlock()
+		if event.Name != "watched-config" {
+			return
+		}
+		size := ""
+		if event.Instance != nil {
+			size = fmt.Sprint(event.Instance(context.Background()).(memory.Config).DefaultPageSize)
+		}
+		received = append(received, event.Kind.String()+":"+size)
+		delivered <- struct{}{}
+	})
+
+	producer := func(size uint) config.Producer {
+		return func(ctx context.Context) config.Config { return memory.Config{DefaultPageSize: size} }
+	}
+	_ = config.Register("watched-config", producer(1))
+	_, _ = config.Replace("watched-config", producer(2))
+	_ = config.Unregister("watched-config")
+	for i := 0; i < 3; i++ {
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Fatal("event hasn't been delivered")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(received) != "[registered:1 replaced:2 unregistered:]" {
+		t.Fatalf("unexpected events %v", received)
+	}
+}
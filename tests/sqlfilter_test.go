@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"database/sql"
+	"github.com/hard-simple/go-dao/pkg/contract/filter"
+	"github.com/hard-simple/go-dao/pkg/contract/filter/sqlfilter"
+	"reflect"
+	"testing"
+)
+
+var userColumns = map[string]string{
+	"id":     "u.id",
+	"name":   "u.name",
+	"status": "u.status",
+	"age":    "u.age",
+}
+
+func TestSQLFilterCompile(t *testing.T) {
+	f := filter.New().
+		AddField(field("status", filter.Eq, "active")).
+		And(filter.New().Or(
+			filter.New().AddField(field("id", filter.Eq, "USR1")),
+			filter.New().
+				AddField(field("name", filter.Contains, "50%_off!")).
+				AddField(field("age", filter.Between, []int{18, 30})),
+		)).
+		Not(filter.New().AddField(field("name", filter.RegEx, "^admin")))
+
+	err, where, args := sqlfilter.New(sqlfilter.PostgreSQL, userColumns).Compile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `"u"."status" = $1 AND ("u"."id" = $2 OR ("u"."name" LIKE $3 ESCAPE '!' AND "u"."age" BETWEEN $4 AND $5))` +
+		` AND NOT "u"."name" ~ $6`
+	if where != expected {
+		t.Fatalf("unexpected where:\n%s\n%s", where, expected)
+	}
+	expectedArgs := []any{"active", "USR1", "%50!%!_off!!%", 18, 30, "^admin"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestSQLFilterDialects(t *testing.T) {
+	f := filter.New().
+		AddField(field("name", filter.StartWith, "Jo")).
+		AddField(field("age", filter.Gte, 18)).
+		AddField(field("status", filter.Eq, nil))
+
+	err, where, args := sqlfilter.New(sqlfilter.MySQL, userColumns).Compile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if where != "`u`.`name` LIKE ? ESCAPE '!' AND `u`.`age` >= ? AND `u`.`status` IS NULL" {
+		t.Fatalf("unexpected where %s", where)
+	}
+	if !reflect.DeepEqual(args, []any{"Jo%", 18}) {
+		t.Fatalf("unexpected args %v", args)
+	}
+
+	err, where, args = sqlfilter.New(sqlfilter.Oracle, userColumns).CompileFrom(
+		filter.New().AddField(field("name", filter.RegEx, "x")).AddField(field("age", filter.Lt, 3)), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if where != `REGEXP_LIKE("u"."name", :p3) AND "u"."age" < :p4` {
+		t.Fatalf("unexpected where %s", where)
+	}
+	if !reflect.DeepEqual(args, []any{sql.Named("p3", "x"), sql.Named("p4", 3)}) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestSQLFilterErrors(t *testing.T) {
+	c := sqlfilter.New(sqlfilter.PostgreSQL, userColumns)
+
+	invalid := []filter.FieldExpression{
+		field("password", filter.Eq, "x"),
+		field("name", filter.Contains, 1),
+		field("age", filter.Between, []int{1, 2, 3}),
+		field("age", filter.Gt, nil),
+		field("age", filter.Undefined, 1),
+	}
+	for _, fe := range invalid {
+		if err, _, _ := c.Compile(filter.New().AddField(fe)); err == nil {
+			t.Errorf("expected error for %s", fe.Name())
+		}
+	}
+
+	err, where, args := c.Compile(filter.New())
+	if err != nil || where != "" || len(args) != 0 {
+		t.Fatalf("empty filter must produce empty where, got %q %v %v", where, args, err)
+	}
+}